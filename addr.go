@@ -0,0 +1,142 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"net/netip"
+)
+
+// 'Addr' is the same as 'IP' but takes golang 'netip.Addr' object,
+// so it doesn't need to be formatted and parsed again.
+//
+// NOTE! The zone of 'ip' (if it is) isn't sent to the Web API,
+// but it's kept in the returned 'Response' object (see 'Response.Addr').
+func (c *Client) Addr(ip netip.Addr) (*Response, error) {
+	if err := c.validate(); err != nil {
+		return nil, &OpError{Op: OpIP, IPs: []string{ip.String()}, Err: err}
+	}
+	r, err := decodeOne(c.baseReq.Addr(ip))
+	if err == nil {
+		r.zone = ip.Zone()
+	}
+	return r, err
+}
+
+// 'Addrs' is the same as 'IPs' but takes golang 'netip.Addr' objects,
+// so they don't need to be formatted and parsed again.
+//
+// NOTE! As well as 'IPs' it skips invalid IP addresses, and the zones
+// of passed IP addresses are kept in the returned 'Response' objects
+// (see 'Response.Addr').
+func (c *Client) Addrs(ips ...netip.Addr) ([]*Response, error) {
+	if err := c.validate(); err != nil {
+		return nil, &OpError{Op: OpIPs, IPs: addrStrings(ips), Err: err}
+	}
+	rs, err := decodeMany(c.baseReq.Addrs(ips...))
+	if err != nil {
+		return nil, err
+	}
+	// Responses are in the same order as valid passed IP addresses
+	i := 0
+	for _, ip := range ips {
+		if ip.IsValid() && i < len(rs) {
+			rs[i].zone = ip.Zone()
+			i++
+		}
+	}
+	return rs, nil
+}
+
+// 'Addr' is the same as 'IP' but takes golang 'netip.Addr' object.
+// It's canonicalized w/o formatting to the string and parsing it again.
+// The zone of 'ip' is dropped, because Web API doesn't support it.
+func (r *Request) Addr(ip netip.Addr) *RawResponse {
+	rr := newRawResponse(OpIP, []string{ip.String()})
+	if err := r.validate(); err != nil {
+		return rr.fail(err)
+	}
+	if !ip.IsValid() {
+		return rr.fail(ErrInvalidIP)
+	}
+	return r.do(rr, canonicalAddr(ip))
+}
+
+// 'Addrs' is the same as 'IPs' but takes golang 'netip.Addr' objects.
+// They are canonicalized w/o formatting to the strings and parsing
+// them again. Zones of IP addresses are dropped, because Web API
+// doesn't support them.
+func (r *Request) Addrs(ips ...netip.Addr) *RawResponse {
+	rr := newRawResponse(OpIPs, addrStrings(ips))
+	if err := r.validate(); err != nil {
+		return rr.fail(err)
+	}
+	if len(ips) == 0 {
+		return rr.fail(ErrNoIP)
+	}
+	canonicals := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		if ip.IsValid() {
+			canonicals = append(canonicals, canonicalAddr(ip))
+		}
+	}
+	return r.bulk(rr, canonicals)
+}
+
+// 'Addr' returns the requested IP address ('IP' field) as golang
+// 'netip.Addr' object. If 'IP' field isn't valid IP address,
+// the zero 'netip.Addr' object is returned.
+//
+// If the current object is got by 'Addr' or 'Addrs' method of 'Client',
+// the returned IP address has the same zone as requested one.
+// The zone is kept when the current object is encoded to JSON
+// and decoded back (see 'MarshalJSON').
+func (r *Response) Addr() netip.Addr {
+	if r == nil {
+		return netip.Addr{}
+	}
+	ip, err := netip.ParseAddr(r.IP)
+	if err != nil {
+		return netip.Addr{}
+	}
+	if r.zone != "" && ip.Is6() {
+		ip = ip.WithZone(r.zone)
+	}
+	return ip
+}
+
+// 'canonicalAddr' is the same as 'canonicalIP' but for golang 'netip.Addr'
+// object: IPv4-mapped IPv6 addresses become IPv4, and the zone is dropped.
+func canonicalAddr(ip netip.Addr) string {
+	return ip.Unmap().WithZone("").String()
+}
+
+// 'addrStrings' returns the text forms of 'ips'.
+// It used to fill 'IPs' field of 'OpError' object.
+func addrStrings(ips []netip.Addr) []string {
+	s := make([]string, len(ips))
+	for i := range ips {
+		s[i] = ips[i].String()
+	}
+	return s
+}
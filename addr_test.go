@@ -0,0 +1,81 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func TestAddrKeepsZone(t *testing.T) {
+	var queried string
+	c := newFakeClient(t, tFakeTransport(func(req *http.Request) (int, string) {
+		queried = req.URL.Path
+		return echoJSON(req)
+	}))
+	ip := netip.MustParseAddr("fe80::1%eth0")
+	res, err := c.Addr(ip)
+	if err != nil {
+		t.Fatalf("Addr: %v", err)
+	}
+	if queried != "/fe80::1" {
+		t.Fatalf("Addr: zone is sent to Web API: %q", queried)
+	}
+	if res.Addr() != ip {
+		t.Fatalf("Addr: got %v, expected %v", res.Addr(), ip)
+	}
+	b, err := json.Marshal(res)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var decoded Response
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if decoded.Addr() != ip {
+		t.Fatalf("round-trip: got %v, expected %v (%s)", decoded.Addr(), ip, b)
+	}
+	if decoded.Extra != nil {
+		t.Fatalf("round-trip: zone is in Extra: %v", decoded.Extra)
+	}
+}
+
+func TestAddrsWithoutZone(t *testing.T) {
+	c := newFakeClient(t, tFakeTransport(echoJSON))
+	ips := []netip.Addr{netip.MustParseAddr("::ffff:1.2.3.4"), {}, netip.MustParseAddr("2001:db8::1")}
+	rs, err := c.Addrs(ips...)
+	if err != nil {
+		t.Fatalf("Addrs: %v", err)
+	}
+	if len(rs) != 2 || rs[0].Addr() != netip.MustParseAddr("1.2.3.4") || rs[1].Addr() != ips[2] {
+		t.Fatalf("Addrs: got %v, %v", rs[0].Addr(), rs[len(rs)-1].Addr())
+	}
+	b, err := json.Marshal(rs[0])
+	if err != nil || strings.Contains(string(b), cZoneKey) {
+		t.Fatalf("Marshal: got %s, %v", b, err)
+	}
+}
@@ -0,0 +1,324 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// Default values of async lookups behaviour.
+const (
+	// How much async lookups can be performed at the same time
+	cAsyncDefaultWorkers int = 8
+	// How much async lookups can wait for the free worker
+	cAsyncDefaultQueue int = 64
+)
+
+// Errors of async lookups.
+var (
+	// 'ErrQueueFull' is the error of 'Future' or 'BulkFuture' object
+	// that has been rejected, because all workers are busy and the queue
+	// of async lookups is full. The lookup hasn't been performed.
+	ErrQueueFull = fmt.Errorf("Async queue is full")
+	// 'ErrNotReady' is returned by 'Result' method of 'Future' or
+	// 'BulkFuture' object which lookup hasn't been completed yet.
+	ErrNotReady = fmt.Errorf("Async lookup isn't completed yet")
+	// 'ErrClientClosed' is the error of 'Future' or 'BulkFuture' object
+	// that has been rejected, because 'Close' method of 'Client' has been
+	// called. The lookup hasn't been performed.
+	ErrClientClosed = fmt.Errorf("Client is closed")
+)
+
+// 'tFuture' is the internal private type that represents the common part
+// of 'Future' and 'BulkFuture' classes.
+type tFuture struct {
+	done chan struct{}
+	err  error
+}
+
+// 'Future' represents the result of async lookup of one IP address,
+// that will be available in the future.
+// Objects of this class are returned by 'IPAsync' method of 'Client' class.
+//
+// Use 'Done' to get a channel that will be closed when lookup is completed,
+// 'Wait' to wait for the result, or 'Result' to get the result
+// w/o waiting.
+type Future struct {
+	tFuture
+	res *Response
+}
+
+// 'BulkFuture' is the same as 'Future' but represents the result
+// of async lookup of a few IP addresses.
+// Objects of this class are returned by 'IPsAsync' method of 'Client' class.
+type BulkFuture struct {
+	tFuture
+	res []*Response
+}
+
+// 'ParamAsyncWorkers' creates a parameter for 'Client' constructors that
+// specifies how much async lookups ('IPAsync', 'IPsAsync') can be performed
+// at the same time. Values less than 1 are treated as 1
+// (and reported as invalid in strict mode, see 'ParamStrict').
+func ParamAsyncWorkers(n int) tClientParam {
+	return func(c *Client) {
+		if c != nil {
+			c.noteParam("async workers", strconv.Itoa(n))
+			if n < 1 {
+				c.paramError(fmt.Errorf("%w: %d async workers", ErrInvalidParam, n))
+				n = 1
+			}
+			c.asyncWorkers = n
+		}
+	}
+}
+
+// 'ParamAsyncQueue' creates a parameter for 'Client' constructors that
+// specifies how much async lookups can wait for the free worker.
+// If queue is full, async lookups are rejected with 'ErrQueueFull' error.
+// 0 means that async lookup is accepted only if some worker is free.
+// Negative values are treated as 0 (and reported as invalid in strict mode,
+// see 'ParamStrict').
+func ParamAsyncQueue(n int) tClientParam {
+	return func(c *Client) {
+		if c != nil {
+			c.noteParam("async queue", strconv.Itoa(n))
+			if n < 0 {
+				c.paramError(fmt.Errorf("%w: %d async queue size", ErrInvalidParam, n))
+				n = 0
+			}
+			c.asyncQueue = n
+		}
+	}
+}
+
+// 'IPAsync' is the non-blocking version of 'IP' method.
+// It starts the lookup of 'ip' in the internal worker pool of the current
+// 'Client' object and returns 'Future' object immediately.
+//
+// If all workers are busy and the queue is full, the returned 'Future'
+// object is already completed with 'ErrQueueFull' error.
+// See 'ParamAsyncWorkers' and 'ParamAsyncQueue' docs.
+func (c *Client) IPAsync(ip string) *Future {
+	f := &Future{tFuture: newFuture()}
+	f.submit(c, func() {
+		f.res, f.err = c.IP(ip)
+	})
+	return f
+}
+
+// 'IPsAsync' is the non-blocking version of 'IPs' method.
+// It works the same way as 'IPAsync' but returns 'BulkFuture' object.
+func (c *Client) IPsAsync(ips ...string) *BulkFuture {
+	f := &BulkFuture{tFuture: newFuture()}
+	f.submit(c, func() {
+		f.res, f.err = c.IPs(ips...)
+	})
+	return f
+}
+
+// 'async' is the internal private auxiliary method of 'Client' class.
+// It passes 'task' to the worker pool (starting it on the first call).
+// If the queue is full, 'ErrQueueFull' is returned and 'task' isn't run.
+// If the current object is closed, 'ErrClientClosed' is returned.
+//
+// Each task takes a slot until it's completed. The number of slots is
+// the number of workers plus the size of the queue, so the task is rejected
+// only if all workers are busy and the queue is full indeed.
+func (c *Client) async(task func()) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+	c.asyncMu.Lock()
+	defer c.asyncMu.Unlock()
+	if c.asyncClosed {
+		return ErrClientClosed
+	}
+	if c.asyncTasks == nil {
+		c.asyncSlots = make(chan struct{}, c.asyncWorkers+c.asyncQueue)
+		c.asyncTasks = make(chan func(), c.asyncWorkers+c.asyncQueue)
+		for i := 0; i < c.asyncWorkers; i++ {
+			go func(tasks <-chan func()) {
+				for task := range tasks {
+					task()
+					<-c.asyncSlots
+				}
+			}(c.asyncTasks)
+		}
+	}
+	// Sending never blocks, because the buffer of tasks is the same
+	// as the number of slots
+	select {
+	case c.asyncSlots <- struct{}{}:
+		c.asyncTasks <- task
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// 'Close' stops the worker pool of async lookups ('IPAsync', 'IPsAsync')
+// of the current object. Already accepted async lookups are completed,
+// and then workers are stopped. New async lookups are rejected
+// with 'ErrClientClosed' error.
+//
+// NOTE! Only async lookups are affected. All other methods can be used
+// after 'Close' as well as before. It's safe to call 'Close' more than once.
+func (c *Client) Close() error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+	c.asyncMu.Lock()
+	defer c.asyncMu.Unlock()
+	if !c.asyncClosed {
+		c.asyncClosed = true
+		if c.asyncTasks != nil {
+			close(c.asyncTasks)
+		}
+	}
+	return nil
+}
+
+// 'newFuture' creates a new not completed 'tFuture' object.
+func newFuture() tFuture {
+	return tFuture{done: make(chan struct{})}
+}
+
+// 'submit' is the internal private auxiliary method of 'tFuture' class.
+// It runs 'task' asynchronously using the worker pool of 'c' and
+// completes the current object when 'task' is done, or right away
+// with an error if 'task' can't be run.
+func (f *tFuture) submit(c *Client, task func()) {
+	err := c.async(func() {
+		defer close(f.done)
+		task()
+	})
+	if err != nil {
+		f.complete(err)
+	}
+}
+
+// 'complete' completes the current object with 'err' error
+// w/o performing any lookup.
+func (f *tFuture) complete(err error) {
+	f.err = err
+	close(f.done)
+}
+
+// 'ready' reports whether lookup is completed.
+func (f *tFuture) ready() bool {
+	select {
+	case <-f.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// 'Done' returns the channel that will be closed when lookup is completed.
+func (f *tFuture) Done() <-chan struct{} {
+	return f.done
+}
+
+// 'Wait' waits until lookup is completed and returns its result.
+// If 'ctx' is done before, the error of 'ctx' is returned
+// (but lookup isn't cancelled and its result will be available later).
+func (f *Future) Wait(ctx context.Context) (*Response, error) {
+	if err := f.wait(ctx); err != nil {
+		return nil, err
+	}
+	return f.res, f.err
+}
+
+// 'Result' returns the result of lookup w/o waiting.
+// If lookup isn't completed yet, 'ErrNotReady' error is returned.
+func (f *Future) Result() (*Response, error) {
+	if !f.ready() {
+		return nil, ErrNotReady
+	}
+	return f.res, f.err
+}
+
+// 'Wait' is the same as 'Wait' of 'Future' class
+// but for the lookup of a few IP addresses.
+func (f *BulkFuture) Wait(ctx context.Context) ([]*Response, error) {
+	if err := f.wait(ctx); err != nil {
+		return nil, err
+	}
+	return f.res, f.err
+}
+
+// 'Result' is the same as 'Result' of 'Future' class
+// but for the lookup of a few IP addresses.
+func (f *BulkFuture) Result() ([]*Response, error) {
+	if !f.ready() {
+		return nil, ErrNotReady
+	}
+	return f.res, f.err
+}
+
+// 'wait' is the internal private auxiliary method of 'tFuture' class.
+// It waits until lookup is completed or 'ctx' is done.
+// If 'ctx' is nil, it waits until lookup is completed.
+func (f *tFuture) wait(ctx context.Context) error {
+	if ctx == nil {
+		<-f.done
+		return nil
+	}
+	select {
+	case <-f.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// 'IPAsync' is the same as 'IPAsync' of any 'Client' instance
+// but only for default client.
+// See docs for 'Client.IPAsync' method and 'DefaultClient' variable for details.
+//
+// NOTE! If there is no default client, the returned 'Future' object is
+// already completed with the same error as 'IP' function returns.
+func IPAsync(ip string) *Future {
+	if c := Default(); c != nil {
+		return c.IPAsync(ip)
+	}
+	f := &Future{tFuture: newFuture()}
+	f.complete(&OpError{Op: OpIP, IPs: []string{ip}, Err: ErrNoDefaultClient})
+	return f
+}
+
+// 'IPsAsync' is the same as 'IPsAsync' of any 'Client' instance
+// but only for default client.
+// See docs for 'Client.IPsAsync' method and 'DefaultClient' variable for details.
+func IPsAsync(ips ...string) *BulkFuture {
+	if c := Default(); c != nil {
+		return c.IPsAsync(ips...)
+	}
+	f := &BulkFuture{tFuture: newFuture()}
+	f.complete(&OpError{Op: OpIPs, IPs: ips, Err: ErrNoDefaultClient})
+	return f
+}
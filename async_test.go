@@ -0,0 +1,150 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestFuturesKeepTheirResults(t *testing.T) {
+	c := newFakeClient(t, tFakeTransport(slowFirst), ParamAsyncWorkers(4))
+	defer c.Close()
+	ips := []string{"1.0.0.1", "1.0.0.2", "1.0.0.3", "1.0.0.4", "1.0.0.5"}
+	futures := make([]*Future, len(ips))
+	for i, ip := range ips {
+		futures[i] = c.IPAsync(ip)
+	}
+	bulk := c.IPsAsync("1.0.0.9", "1.0.0.1", "1.0.0.9")
+	invalid := c.IPAsync("bogus")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for i, f := range futures {
+		res, err := f.Wait(ctx)
+		if err != nil || res.IP != ips[i] {
+			t.Fatalf("future %d: got %+v, %v, expected %s", i, res, err, ips[i])
+		}
+		if res2, err2 := f.Result(); res2 != res || err2 != nil {
+			t.Fatalf("future %d: Result differs from Wait", i)
+		}
+	}
+	rs, err := bulk.Wait(ctx)
+	if err != nil {
+		t.Fatalf("bulk future: %v", err)
+	}
+	got := []string{}
+	for _, r := range rs {
+		got = append(got, r.IP)
+	}
+	if want := []string{"1.0.0.9", "1.0.0.1", "1.0.0.9"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("bulk future: got %v, expected %v", got, want)
+	}
+	if _, err := invalid.Wait(ctx); !errors.Is(err, ErrInvalidIP) {
+		t.Fatalf("invalid future: got %v, expected invalid IP error", err)
+	}
+}
+
+func TestFutureQueueFullAndNotReady(t *testing.T) {
+	unblock := make(chan struct{})
+	c := newFakeClient(t, tFakeTransport(func(req *http.Request) (int, string) {
+		<-unblock
+		return echoJSON(req)
+	}), ParamAsyncWorkers(1), ParamAsyncQueue(0))
+	defer c.Close()
+	first := c.IPAsync("1.1.1.1")
+	if _, err := first.Result(); err != ErrNotReady {
+		t.Fatalf("Result: got %v, expected ErrNotReady", err)
+	}
+	if _, err := c.IPsAsync("2.2.2.2").Wait(nil); err != ErrQueueFull {
+		t.Fatalf("second lookup: got %v, expected ErrQueueFull", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := first.Wait(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Wait: got %v, expected deadline error", err)
+	}
+	close(unblock)
+	if res, err := first.Wait(nil); err != nil || res.IP != "1.1.1.1" {
+		t.Fatalf("Wait: got %+v, %v", res, err)
+	}
+}
+
+func TestCloseStopsWorkers(t *testing.T) {
+	before := runtime.NumGoroutine()
+	c := newFakeClient(t, tFakeTransport(echoJSON), ParamAsyncWorkers(16))
+	f := c.IPAsync("1.1.1.1")
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	// Accepted lookup is completed
+	if _, err := f.Wait(nil); err != nil {
+		t.Fatalf("accepted lookup: %v", err)
+	}
+	if _, err := c.IPAsync("1.1.1.1").Wait(nil); err != ErrClientClosed {
+		t.Fatalf("lookup after Close: got %v, expected ErrClientClosed", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before+2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines are still running, %d before", runtime.NumGoroutine(), before)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestAsyncWithoutDefaultClient(t *testing.T) {
+	keepDefault(t)
+	SetDefault(nil)
+
+	f := IPAsync("1.1.1.1")
+	if _, err := f.Result(); err == ErrNotReady {
+		t.Fatal("IPAsync: future isn't completed")
+	}
+	_, syncErr := IP("1.1.1.1")
+	_, asyncErr := f.Wait(nil)
+	_, bulkErr := IPsAsync("1.1.1.1", "2.2.2.2").Wait(nil)
+
+	// Sync and async errors are checked the same way
+	for name, err := range map[string]error{"IP": syncErr, "IPAsync": asyncErr, "IPsAsync": bulkErr} {
+		var opErr *OpError
+		if !errors.As(err, &opErr) || !errors.Is(err, ErrNoDefaultClient) {
+			t.Fatalf("%s: got %v, expected OpError with ErrNoDefaultClient", name, err)
+		}
+	}
+	if !reflect.DeepEqual(asyncErr, syncErr) {
+		t.Fatalf("IPAsync: got %#v, expected %#v", asyncErr, syncErr)
+	}
+	var opErr *OpError
+	if !errors.As(bulkErr, &opErr) || opErr.Op != OpIPs || len(opErr.IPs) != 2 {
+		t.Fatalf("IPsAsync: got %+v", opErr)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// 'Codec' is the JSON decoder used by 'CheckError' and 'DecodeTo' methods
+// of 'RawResponse' (and so by all methods of 'Client' that decode
+// responses). By default 'encoding/json' package is used.
+//
+// Pass your own implementation (a faster JSON library, for example)
+// using 'ParamCodec' parameter of 'Client' constructor or 'Codec' method
+// of 'Request'.
+//
+// 'Response' objects (and their nested 'TimeZone', 'Security' objects)
+// are decoded using 'Codec' too. But if these types are fields of your
+// own structs, 'Unmarshal' must call their 'UnmarshalJSON' methods
+// (as 'encoding/json' does), and they use 'encoding/json' then.
+//
+// NOTE! 'Unmarshal' must support 'json.RawMessage' destinations
+// (and maps and slices of them) as 'encoding/json' does, because that's how
+// 'Response' objects are split to their parts.
+type Codec interface {
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// 'tJSONCodec' is the default 'Codec' that uses 'encoding/json' package.
+type tJSONCodec struct{}
+
+// 'defaultCodec' is used when 'Codec' hasn't been specified.
+var defaultCodec Codec = tJSONCodec{}
+
+// 'Unmarshal' implements the 'Codec' interface for 'tJSONCodec' class.
+func (tJSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// 'Codec' specifies the JSON decoder used to check errors and decode
+// the responses of the current object (see 'Codec' interface).
+// nil means the default decoder ('encoding/json').
+func (r *Request) Codec(codec Codec) *Request {
+	if r == nil {
+		return nil
+	}
+	r.codec = codec
+	return r
+}
+
+// 'ParamCodec' creates a parameter for 'Client' constructors that
+// specifies the JSON decoder (see 'Codec' interface).
+func ParamCodec(codec Codec) tClientParam {
+	return func(c *Client) {
+		if c != nil {
+			c.noteParam("codec", fmt.Sprintf("%T", codec))
+			c.baseReq = c.baseReq.Codec(codec)
+		}
+	}
+}
+
+// 'getCodec' returns the 'Codec' of the current object
+// or the default one.
+func (r *RawResponse) getCodec() Codec {
+	if r.codec == nil {
+		return defaultCodec
+	}
+	return r.codec
+}
+
+// 'tCodecDecoder' is implemented by types of this package that decode
+// themselves from JSON ('Response', 'TimeZone', 'Security').
+// Unlike 'json.Unmarshaler', it takes the 'Codec' to decode with.
+type tCodecDecoder interface {
+	decodeJSON(codec Codec, data []byte) error
+}
+
+// 'unmarshalWith' decodes 'data' JSON to 'dst' using 'codec'.
+// Types that decode themselves (see 'tCodecDecoder') and slices
+// of 'Response' objects are decoded by themselves, so their nested values
+// are decoded using 'codec' too.
+func unmarshalWith(codec Codec, data []byte, dst interface{}) error {
+	switch d := dst.(type) {
+	case tCodecDecoder:
+		return d.decodeJSON(codec, data)
+	case *[]*Response:
+		rs, err := decodeResponses(codec, data)
+		if err == nil {
+			*d = rs
+		}
+		return err
+	case *[]Response:
+		rs, err := decodeResponses(codec, data)
+		if err == nil {
+			*d = nil
+			for _, r := range rs {
+				if r == nil {
+					r = &Response{}
+				}
+				*d = append(*d, *r)
+			}
+		}
+		return err
+	}
+	return codec.Unmarshal(data, dst)
+}
+
+// 'decodeResponses' decodes 'data' JSON array to 'Response' objects
+// using 'codec'. Null items are decoded as nil.
+func decodeResponses(codec Codec, data []byte) ([]*Response, error) {
+	var items []json.RawMessage
+	if err := codec.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	if items == nil {
+		return nil, nil // null
+	}
+	rs := make([]*Response, len(items))
+	for i, item := range items {
+		if isJSONNull(item) {
+			continue
+		}
+		rs[i] = &Response{}
+		if err := rs[i].decodeJSON(codec, item); err != nil {
+			return nil, err
+		}
+	}
+	return rs, nil
+}
+
+// 'isJSONNull' reports whether 'v' is null JSON value.
+func isJSONNull(v []byte) bool {
+	return bytes.Equal(bytes.TrimSpace(v), []byte("null"))
+}
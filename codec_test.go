@@ -0,0 +1,225 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// 'fullResponseJSON' is the response of Web API with all fields.
+const fullResponseJSON = `{"ip":"134.201.250.155","hostname":"134.201.250.155","type":"ipv4",` +
+	`"continent_code":"NA","continent_name":"North America","country_code":"US",` +
+	`"country_name":"United States","region_code":"CA","region_name":"California",` +
+	`"city":"Los Angeles","zip":"90013","latitude":34.0453,"longitude":-118.2413,` +
+	`"location":{"geoname_id":5368361,"capital":"Washington D.C.","languages":` +
+	`[{"code":"en","name":"English","native":"English"}],"country_flag":"https://assets.ipstack.com/images/assets/flags_svg/us.svg",` +
+	`"country_flag_emoji":"🇺🇸","country_flag_emoji_unicode":"U+1F1FA U+1F1F8","calling_code":"1","is_eu":false},` +
+	`"time_zone":{"id":"America/Los_Angeles","current_time":"2018-03-29T07:35:08-07:00",` +
+	`"gmt_offset":-25200,"code":"PDT","is_daylight_saving":true},` +
+	`"currency":{"code":"USD","name":"US Dollar","plural":"US dollars","symbol":"$","symbol_native":"$"},` +
+	`"connection":{"asn":25876,"isp":"Los Angeles Department of Water & Power"},` +
+	`"security":{"is_proxy":false,"proxy_type":null,"is_crawler":false,"crawler_name":null,` +
+	`"crawler_type":null,"is_tor":false,"threat_level":"low","threat_types":null}}`
+
+// 'tBenchTarget' is the user's destination struct of benchmarks.
+type tBenchTarget struct {
+	IP          string  `json:"ip"`
+	CountryCode string  `json:"country_code"`
+	City        string  `json:"city"`
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+}
+
+// 'tRecordingCodec' is the 'Codec' that uses 'encoding/json'
+// and records the data of each 'Unmarshal' call.
+type tRecordingCodec struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+// 'Unmarshal' implements the 'Codec' interface for 'tRecordingCodec' class.
+func (c *tRecordingCodec) Unmarshal(data []byte, v interface{}) error {
+	c.mu.Lock()
+	c.calls = append(c.calls, string(data))
+	c.mu.Unlock()
+	return json.Unmarshal(data, v)
+}
+
+// 'count' returns how much times 'data' has been decoded.
+func (c *tRecordingCodec) count(data string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for _, call := range c.calls {
+		if call == data {
+			n++
+		}
+	}
+	return n
+}
+
+// 'replyWith' returns the fake transport that always responds with 'body'.
+func replyWith(body string) tFakeTransport {
+	return func(*http.Request) (int, string) {
+		return http.StatusOK, body
+	}
+}
+
+func TestCodecDecodesResponse(t *testing.T) {
+	body := strings.Replace(fullResponseJSON, `"threat_types":null`, `"threat_types":["tor"]`, 1)
+	body = strings.Replace(body, `"zip":"90013"`, `"zip":"90013","extra":1`, 1)
+	codec := &tRecordingCodec{}
+	c := newFakeClient(t, replyWith(body), ParamCodec(codec))
+
+	res, err := c.IP("134.201.250.155")
+	if err != nil {
+		t.Fatalf("IP: %v", err)
+	}
+	if codec.count(body) == 0 {
+		t.Error("response hasn't been decoded by codec")
+	}
+	// Nested types that decode themselves use the codec too
+	security := body[strings.Index(body, `{"is_proxy"`) : len(body)-1]
+	if codec.count(`"2018-03-29T07:35:08-07:00"`) != 1 || codec.count(security) == 0 {
+		t.Errorf("time zone or security haven't been decoded by codec: %q", codec.calls)
+	}
+	if res.Timezone == nil || res.Timezone.CurrentTime.IsZero() {
+		t.Errorf("current time isn't decoded: %+v", res.Timezone)
+	}
+	if res.Security == nil || !res.Security.ThreatTypes.Has(ThreatTOR) ||
+		res.Security.ThreatLevel != ThreatLevelLow {
+		t.Errorf("security isn't decoded: %+v", res.Security)
+	}
+	if !res.Has(FieldLocationLanguagesCode) || !res.Has(FieldTimeZoneCurrentTime) ||
+		res.Has(FieldSecurityProxyType) {
+		t.Error("wrong set of present fields")
+	}
+	if string(res.Extra["extra"]) != "1" || len(res.Extra) != 1 {
+		t.Errorf("Extra = %v, want only 'extra' field", res.Extra)
+	}
+	if res.Lat() != 34.0453 {
+		t.Errorf("Lat = %v, want 34.0453", res.Lat())
+	}
+}
+
+func TestCodecDetectsAPIError(t *testing.T) {
+	codec := &tRecordingCodec{}
+	c := newFakeClient(t, replyWith(usageLimitJSON), ParamCodec(codec))
+
+	if _, err := c.IP("1.1.1.1"); !IsQuotaExceeded(err) {
+		t.Fatalf("IP: got %v, want usage limit error", err)
+	}
+	dst := tBenchTarget{City: "kept"}
+	err := c.DecodeIP("1.1.1.1", &dst)
+	var errApi *APIErr
+	if !errors.As(err, &errApi) || errApi.Code() != 104 {
+		t.Fatalf("DecodeIP: got %v, want usage limit error", err)
+	}
+	if dst.City != "kept" {
+		t.Errorf("destination has been changed on error: %+v", dst)
+	}
+	if codec.count(usageLimitJSON) != 2 {
+		t.Errorf("error hasn't been checked by codec: %q", codec.calls)
+	}
+}
+
+func TestCodecDecodesStructsAndBulk(t *testing.T) {
+	codec := &tRecordingCodec{}
+	c := newFakeClient(t, replyWith(fullResponseJSON), ParamCodec(codec))
+	dst := tBenchTarget{}
+	if err := c.DecodeIP("134.201.250.155", &dst); err != nil {
+		t.Fatalf("DecodeIP: %v", err)
+	}
+	if dst.City != "Los Angeles" || dst.Latitude != 34.0453 {
+		t.Errorf("wrong decoded struct: %+v", dst)
+	}
+
+	// The response of bulk request is split to items by codec too
+	body := `[{"ip":"1.1.1.1"},{"ip":"2.2.2.2"}]`
+	codec = &tRecordingCodec{}
+	c = newFakeClient(t, replyWith(body), ParamCodec(codec))
+	rs, err := c.IPs("1.1.1.1", "2.2.2.2", "1.1.1.1")
+	if err != nil || len(rs) != 3 || rs[2].IP != "1.1.1.1" {
+		t.Fatalf("IPs: %v, %d items", err, len(rs))
+	}
+	if codec.count(body) != 1 {
+		t.Errorf("bulk response hasn't been split by codec: %q", codec.calls)
+	}
+}
+
+// 'decodeBenchmarks' are inputs of 'benchmarkCodec'.
+var decodeBenchmarks = []struct {
+	name   string
+	data   string
+	decode func(rr *RawResponse) error
+}{
+	{"Response", fullResponseJSON, func(rr *RawResponse) error {
+		_, err := decodeOne(rr)
+		return err
+	}},
+	{"Responses", "[" + strings.TrimSuffix(strings.Repeat(fullResponseJSON+",", 50), ",") + "]",
+		func(rr *RawResponse) error {
+			_, err := decodeMany(rr)
+			return err
+		}},
+	{"Struct", fullResponseJSON, func(rr *RawResponse) error {
+		var dst tBenchTarget
+		return rr.decode(&dst)
+	}},
+	{"APIError", usageLimitJSON, func(rr *RawResponse) error {
+		if _, err := decodeOne(rr); !IsQuotaExceeded(err) {
+			return fmt.Errorf("usage limit error is expected, got %v", err)
+		}
+		return nil
+	}},
+}
+
+// 'benchmarkCodec' runs decoding benchmarks with 'codec',
+// so different codecs can be compared on the same inputs.
+func benchmarkCodec(b *testing.B, codec Codec) {
+	for _, bm := range decodeBenchmarks {
+		bm := bm
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(bm.data)))
+			for i := 0; i < b.N; i++ {
+				rr := newRawResponse(OpIP, nil)
+				rr.RawData, rr.codec = []byte(bm.data), codec
+				if err := bm.decode(rr); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	benchmarkCodec(b, defaultCodec)
+}
@@ -0,0 +1,210 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// Types that decode themselves. Structs of these types are treated
+// as one field, not as the set of fields.
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// 'FieldsOf' returns the set of fields that 'v' struct can be decoded from.
+// 'v' might be the struct, the pointer to the struct or the slice (array)
+// of them (or pointer to it).
+//
+// Fields are derived from 'json' tags of the struct's fields
+// (or from the names of fields if tags are missing), as 'encoding/json'
+// package does it (case-insensitively). Nested structs (or slices
+// of structs) of parent fields (like 'location' or 'location.languages')
+// are walked recursively, so "location.capital", "location.languages.code"
+// fields might be derived.
+// Embedded structs are walked as their fields are the fields of 'v'.
+//
+// If some field of struct isn't ipstack field, the error is returned
+// (wraps 'ErrUnknownField'). Use "json:\"-\"" tag to skip such fields.
+//
+// NOTE! The empty set is returned for struct w/o fields,
+// that means "all fields".
+func FieldsOf(v interface{}) (FieldSet, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && isContainer(t.Kind()) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return FieldSet{}, ErrInvalidDestination
+	}
+	var unknown []Field
+	fs := fieldsOfStruct(t, "", &unknown)
+	if len(unknown) > 0 {
+		return fs, &tUnknownFieldsError{fields: unknown}
+	}
+	return fs, nil
+}
+
+// 'fieldsOfStruct' is the internal private auxiliary function of 'FieldsOf'.
+// It returns the set of fields of 't' struct, which JSON object is
+// the value of 'prefix' field ("" for the root object).
+// The names of unknown fields are appended to 'unknown'.
+func fieldsOfStruct(t reflect.Type, prefix string, unknown *[]Field) FieldSet {
+	var fs FieldSet
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, skip := jsonFieldName(sf)
+		if skip {
+			continue
+		}
+		ft := sf.Type
+		for isContainer(ft.Kind()) {
+			ft = ft.Elem()
+		}
+		// Embedded struct w/o name is flatten by 'encoding/json'
+		if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			fs = fs.Union(fieldsOfStruct(ft, prefix, unknown))
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		field := Field(prefix + name)
+		idx, ok := fieldIndex[field]
+		// 'encoding/json' matches names case-insensitively,
+		// and all ipstack fields are in lower case
+		if !ok {
+			if idx, ok = fieldIndex[Field(strings.ToLower(string(field)))]; ok {
+				field = allFields[idx]
+			}
+		}
+		if !ok {
+			*unknown = append(*unknown, field)
+			continue
+		}
+		// Parent field that is decoded to struct is walked recursively,
+		// but only if struct has at least one field
+		if len(fieldTree[idx].children) > 0 && isWalkable(ft) {
+			if sub := fieldsOfStruct(ft, string(field)+".", unknown); !sub.IsEmpty() {
+				fs = fs.Union(sub)
+				continue
+			}
+		}
+		fs.bits |= expandField(idx)
+	}
+	return fs.normalized()
+}
+
+// 'jsonFieldName' returns the name of 'sf' struct field in JSON object
+// ("" if tag doesn't specify it) and reports whether field must be skipped
+// (unexported or tagged "-").
+func jsonFieldName(sf reflect.StructField) (name string, skip bool) {
+	tag := sf.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	if i := strings.IndexByte(tag, ','); i != -1 {
+		tag = tag[:i]
+	}
+	// Unexported fields are skipped, but embedded structs are not
+	if sf.PkgPath != "" && !sf.Anonymous {
+		return "", true
+	}
+	return tag, false
+}
+
+// 'isContainer' reports whether the value of 'k' kind contains
+// the values of the other type ('Elem' method of 'reflect.Type').
+func isContainer(k reflect.Kind) bool {
+	return k == reflect.Ptr || k == reflect.Slice || k == reflect.Array
+}
+
+// 'isWalkable' reports whether 't' is a struct which fields
+// should be walked, not the struct which decodes itself.
+func isWalkable(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	pt := reflect.PtrTo(t)
+	return !pt.Implements(jsonUnmarshalerType) && !pt.Implements(textUnmarshalerType)
+}
+
+// 'DecodeIP' returns the info about 'ip' decoded to 'dst' which must be
+// the pointer to the struct.
+// Only fields of that struct are requested (see 'FieldsOf' docs),
+// so you do not need to keep the list of fields and your struct in sync.
+func (c *Client) DecodeIP(ip string, dst interface{}) error {
+	if err := c.validate(); err != nil {
+		return &OpError{Op: OpIP, IPs: []string{ip}, Err: err}
+	}
+	return c.baseReq.DecodeIP(ip, dst)
+}
+
+// 'DecodeIPs' is the same as 'DecodeIP' but for a few IP addresses.
+// 'dst' must be the pointer to the slice of structs (or of pointers
+// to structs).
+func (c *Client) DecodeIPs(dst interface{}, ips ...string) error {
+	if err := c.validate(); err != nil {
+		return &OpError{Op: OpIPs, IPs: ips, Err: err}
+	}
+	return c.baseReq.DecodeIPs(dst, ips...)
+}
+
+// 'DecodeIP' performs 'IP' request with fields derived from 'dst' struct
+// (see 'FieldsOf' docs) instead of fields of the current object,
+// checks Web API error and decodes the response to 'dst'.
+// The current object isn't changed.
+func (r *Request) DecodeIP(ip string, dst interface{}) error {
+	fs, err := FieldsOf(dst)
+	if err != nil {
+		return &OpError{Op: OpIP, IPs: []string{ip}, Err: err}
+	}
+	return r.onlyFields(fs).IP(ip).decode(dst)
+}
+
+// 'DecodeIPs' is the same as 'DecodeIP' but performs 'IPs' request.
+// 'dst' must be the pointer to the slice of structs (or of pointers
+// to structs).
+func (r *Request) DecodeIPs(dst interface{}, ips ...string) error {
+	fs, err := FieldsOf(dst)
+	if err != nil {
+		return &OpError{Op: OpIPs, IPs: ips, Err: err}
+	}
+	return r.onlyFields(fs).IPs(ips...).decode(dst)
+}
+
+// 'onlyFields' returns the copy of the current object that requests
+// only 'fs' fields. The current object isn't changed.
+func (r *Request) onlyFields(fs FieldSet) *Request {
+	if r == nil {
+		return nil
+	}
+	cp := r.copy()
+	cp.fields = FieldSet{}
+	return cp.WithFieldSet(fs)
+}
@@ -0,0 +1,152 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"errors"
+	"testing"
+)
+
+type tEmbeddedCountry struct {
+	CountryCode string `json:"country_code"`
+	CountryName string `json:"country_name"`
+}
+
+func TestFieldsOf(t *testing.T) {
+	type language struct {
+		Code string `json:"code"`
+	}
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"Flat", struct {
+			IP   string `json:"ip"`
+			City string `json:"city,omitempty"`
+		}{}, "ip,city"},
+		{"CaseInsensitive", struct {
+			CountryCode string `json:"Country_Code"`
+			Zip         string
+			Latitude    float64
+		}{}, "country_code,zip,latitude"},
+		{"Skipped", struct {
+			IP    string `json:"ip"`
+			Notes string `json:"-"`
+			cache int
+		}{}, "ip"},
+		{"NestedLocation", struct {
+			Location struct {
+				Capital   string     `json:"capital"`
+				Languages []language `json:"languages"`
+			} `json:"location"`
+		}{}, "location.capital,location.languages.code"},
+		{"PointerSubStruct", struct {
+			Location *struct {
+				Capital string
+				IsEU    bool `json:"is_eu"`
+			} `json:"location"`
+			Connection *struct {
+				ASN int `json:"asn"`
+			}
+		}{}, "location.capital,location.is_eu,connection.asn"},
+		{"EmptySubStruct", struct {
+			Location struct{} `json:"location"`
+		}{}, "location"},
+		{"SelfDecodingSubStruct", struct {
+			TimeZone *TimeZone `json:"time_zone"`
+		}{}, "time_zone"},
+		{"Embedded", struct {
+			tEmbeddedCountry
+			Zip string `json:"zip"`
+		}{}, "country_code,country_name,zip"},
+		{"EmbeddedPointer", struct {
+			*tEmbeddedCountry
+		}{}, "country_code,country_name"},
+		{"Pointer", &struct {
+			IP string `json:"ip"`
+		}{}, "ip"},
+		{"Slice", []*struct {
+			City string `json:"city"`
+		}{}, "city"},
+		{"PointerToSlice", &[]struct {
+			Zip string `json:"zip"`
+		}{}, "zip"},
+		{"NoFields", struct{}{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs, err := FieldsOf(tt.v)
+			if err != nil {
+				t.Fatalf("FieldsOf: %v", err)
+			}
+			if got := fs.String(); got != tt.want {
+				t.Fatalf("FieldsOf: got %q, expected %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldsOfUnknown(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		err  string
+		want string
+	}{
+		{"UntaggedID", struct {
+			ID   int
+			City string `json:"city"`
+		}{}, `Unknown field: "ID"`, "city"},
+		{"Nested", struct {
+			Location struct {
+				Capital string `json:"capital"`
+				Street  string `json:"street"`
+			} `json:"location"`
+		}{}, `Unknown field: "location.street"`, "location.capital"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs, err := FieldsOf(tt.v)
+			if !errors.Is(err, ErrUnknownField) {
+				t.Fatalf("FieldsOf: got %v, expected ErrUnknownField", err)
+			}
+			if err.Error() != tt.err {
+				t.Fatalf("Error: got %q, expected %q", err.Error(), tt.err)
+			}
+			// Known fields are returned anyway
+			if got := fs.String(); got != tt.want {
+				t.Fatalf("FieldsOf: got %q, expected %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldsOfInvalidDestination(t *testing.T) {
+	for _, v := range []interface{}{nil, 42, new(string), []int{}} {
+		if _, err := FieldsOf(v); err != ErrInvalidDestination {
+			t.Errorf("FieldsOf(%T): got %v, expected ErrInvalidDestination", v, err)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Predefined consts each of that represents some documented error code
+// of ipstack Web API (see 'Code' method of 'APIErr' class).
+//
+// You can read about them on https://ipstack.com/documentation.
+const (
+	// The access key is missing or invalid.
+	CodeInvalidAccessKey int = 101
+	// The user's account is not active.
+	CodeInactiveUser int = 102
+	// The requested API function does not exist.
+	CodeInvalidAPIFunction int = 103
+	// The maximum allowed amount of monthly API requests has been reached.
+	CodeUsageLimitReached int = 104
+	// The current subscription plan does not support this API function.
+	CodeFunctionAccessRestricted int = 105
+	// The IP address supplied is invalid.
+	CodeInvalidIPAddress int = 106
+	// One or more invalid fields were specified using the 'fields' parameter.
+	CodeInvalidFields int = 301
+	// Too many IPs have been specified for the bulk request.
+	CodeTooManyIPs int = 302
+	// The bulk requests are not supported on the current subscription plan.
+	CodeBatchNotSupported int = 303
+	// The requested resource does not exist.
+	CodeNotFound int = 404
+)
+
+// Predefined sentinel errors each of that represents some documented
+// error of ipstack Web API.
+//
+// Any 'APIErr' object is treated as one of these errors by 'errors.Is'
+// if it has the same code. Thus, you can write:
+// "if errors.Is(err, ipstack.ErrUsageLimitReached) { ... }".
+var (
+	ErrInvalidAccessKey = newAPIErr(CodeInvalidAccessKey,
+		"invalid_access_key", "The access key is missing or invalid.")
+	ErrInactiveUser = newAPIErr(CodeInactiveUser,
+		"inactive_user", "The user's account is not active.")
+	ErrInvalidAPIFunction = newAPIErr(CodeInvalidAPIFunction,
+		"invalid_api_function", "The requested API function does not exist.")
+	ErrUsageLimitReached = newAPIErr(CodeUsageLimitReached,
+		"usage_limit_reached", "The maximum allowed amount of monthly API requests has been reached.")
+	ErrFunctionAccessRestricted = newAPIErr(CodeFunctionAccessRestricted,
+		"function_access_restricted", "The current subscription plan does not support this API function.")
+	ErrInvalidIPAddress = newAPIErr(CodeInvalidIPAddress,
+		"invalid_ip_address", "The IP address supplied is invalid.")
+	ErrInvalidFields = newAPIErr(CodeInvalidFields,
+		"invalid_fields", "One or more invalid fields were specified.")
+	ErrTooManyIPs = newAPIErr(CodeTooManyIPs,
+		"too_many_ips", "Too many IPs have been specified for the bulk request.")
+	ErrBatchNotSupported = newAPIErr(CodeBatchNotSupported,
+		"batch_not_supported_on_plan", "The current subscription plan does not support bulk requests.")
+	ErrNotFound = newAPIErr(CodeNotFound,
+		"404_not_found", "The requested resource does not exist.")
+)
+
+// 'newAPIErr' creates a new 'APIErr' object with 'code', 'typ' and 'info'.
+// It used to create predefined sentinel errors.
+func newAPIErr(code int, typ, info string) *APIErr {
+	return &APIErr{RawCode: code, RawType: typ, RawInfo: info}
+}
+
+// 'Is' reports whether the current object is the same Web API error
+// as 'target'. Errors are the same if their codes are equal.
+//
+// It's used by 'errors.Is' and you shouldn't call it directly.
+func (e *APIErr) Is(target error) bool {
+	t, ok := target.(*APIErr)
+	if !ok || e == nil || t == nil {
+		return false
+	}
+	return e.RawCode == t.RawCode
+}
+
+// 'IsTemporary' reports whether 'err' might disappear if the same request
+// will be performed later: the network error is timeout or the deadline
+// of request context is exceeded.
+//
+// NOTE! The monthly usage limit (104 usage_limit_reached) isn't temporary,
+// because it's reset only at the next billing period. Use 'IsQuotaExceeded'
+// to check it.
+func IsTemporary(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	for ; err != nil; err = unwrap(err) {
+		if te, ok := err.(interface{ Timeout() bool }); ok && te.Timeout() {
+			return true
+		}
+	}
+	return false
+}
+
+// 'IsQuotaExceeded' reports whether 'err' is caused by the monthly usage
+// limit of the current subscription plan (104 usage_limit_reached).
+// Requests will fail until the next billing period or upgrade of the plan.
+func IsQuotaExceeded(err error) bool {
+	return findAPIErr(err).Code() == CodeUsageLimitReached
+}
+
+// 'IsAuth' reports whether 'err' is the authentication error:
+// the access key is missing or invalid (101) or the account
+// isn't active (102).
+func IsAuth(err error) bool {
+	switch findAPIErr(err).Code() {
+	case CodeInvalidAccessKey, CodeInactiveUser:
+		return true
+	}
+	return false
+}
+
+// 'IsPlanRestriction' reports whether 'err' is caused by the limitations
+// of the current subscription plan: the API function isn't available (105)
+// or the bulk requests aren't supported (303).
+func IsPlanRestriction(err error) bool {
+	switch findAPIErr(err).Code() {
+	case CodeFunctionAccessRestricted, CodeBatchNotSupported:
+		return true
+	}
+	return false
+}
+
+// 'findAPIErr' returns the first 'APIErr' object in the chain of 'err'
+// (see 'unwrap'), or nil if there is no such one.
+func findAPIErr(err error) *APIErr {
+	for ; err != nil; err = unwrap(err) {
+		if e, ok := err.(*APIErr); ok {
+			return e
+		}
+	}
+	return nil
+}
+
+// 'unwrap' returns the error wrapped by 'err' (if 'err' has 'Unwrap' method)
+// or nil.
+func unwrap(err error) error {
+	if u, ok := err.(interface{ Unwrap() error }); ok {
+		return u.Unwrap()
+	}
+	return nil
+}
+
+// Predefined consts each of that represents the operation
+// 'OpError' object is the error of.
+const (
+	OpIP     string = "IP"
+	OpIPs    string = "IPs"
+	OpMe     string = "Me"
+	OpNew    string = "New"
+	OpStream string = "Stream"
+	OpJob    string = "Job"
+)
+
+// Predefined errors each of that represents some reason of failure
+// that happens before Web API request is performed (or instead of it).
+// They are wrapped by 'OpError' object, so use 'errors.Is' to check them.
+var (
+	// You tries to call some method of the nil object of client.
+	// For example: (*Client)(nil).<some_method>(...).
+	ErrNilClient = fmt.Errorf("Nil client object")
+	// Probably you create 'Client' object directly and now you tries to call
+	// some method of that object. Use 'New' constructor instead.
+	ErrInvalidClient = fmt.Errorf("Incorrect internal state")
+	// You tries to call some method of the nil 'Request' object.
+	ErrNilRequest = fmt.Errorf("Nil request object")
+	// 'Request' object hasn't golang HTTP client. Probably you create
+	// 'Request' object directly. Use 'R' method of 'Client' instead.
+	ErrNilHTTPClient = fmt.Errorf("Nil http.Client object in request")
+	// You tries to call some method of the nil 'RawResponse' object.
+	ErrNilResponse = fmt.Errorf("Nil RAW response object")
+	// You pass nil as destination to the 'DecodeTo' method.
+	ErrNilDestination = fmt.Errorf("Nil destination argument")
+	// You pass nil as source reader to the streaming lookup or job.
+	ErrNilSource = fmt.Errorf("Nil source reader")
+	// You pass nil as callback to the 'StreamFunc' method.
+	ErrNilCallback = fmt.Errorf("Nil callback")
+	// You tries to call some method of the nil 'Job' object.
+	ErrNilJob = fmt.Errorf("Nil job object")
+	// You pass nil as 'Sink' to the 'Job' method.
+	ErrNilSink = fmt.Errorf("Nil sink")
+	// IP address is empty.
+	ErrEmptyIP = fmt.Errorf("Empty IP")
+	// IP address isn't valid IPv4 or IPv6 address.
+	ErrInvalidIP = fmt.Errorf("Invalid IP")
+	// No one IP address has been passed to the bulk request.
+	ErrNoIP = fmt.Errorf("No IP passed")
+	// No one valid IP address has been passed to the bulk request.
+	ErrNoValidIP = fmt.Errorf("No valid IP passed")
+	// The number of items in the response of bulk request differs from
+	// the number of requested unique IP addresses.
+	ErrResponseMismatch = fmt.Errorf("Unexpected number of items in response")
+	// Body of HTTP response is nil.
+	ErrNilBody = fmt.Errorf("Body of GET response is nil")
+	// Token hasn't been passed to the 'Client' constructor.
+	ErrNoToken = fmt.Errorf("Token argument (string or []byte) is required")
+	// Package level function is called, but default client isn't initialized.
+	ErrNoDefaultClient = fmt.Errorf("DefaultClient client isn't initialized")
+	// Unknown field has been passed to 'NewFieldSet', 'Fields' method
+	// of 'Request' or 'ParamFields' parameter of 'Client' constructor.
+	ErrUnknownField = fmt.Errorf("Unknown field")
+	// Destination of 'FieldsOf', 'DecodeIP' or 'DecodeIPs' isn't a struct,
+	// pointer to the struct or slice of them.
+	ErrInvalidDestination = fmt.Errorf("Destination must be a struct or a slice of structs")
+	// Unsupported language has been passed to 'Language' method of 'Request'
+	// or 'ParamLanguage' parameter of 'Client' constructor.
+	ErrUnknownLanguage = fmt.Errorf("Unknown language")
+	// Unsupported format has been passed to 'Format' method of 'Request'
+	// or 'ParamFormat' parameter of 'Client' constructor.
+	ErrUnknownFormat = fmt.Errorf("Unknown format")
+	// Argument of unsupported type has been passed to 'Client' constructor
+	// in strict mode (see 'ParamStrict').
+	ErrUnknownParam = fmt.Errorf("Unknown argument")
+	// The same option has been passed to 'Client' constructor in strict mode
+	// more than once with different values (see 'ParamStrict').
+	ErrConflictingParams = fmt.Errorf("Conflicting arguments")
+	// Invalid value has been passed to 'Client' constructor in strict mode
+	// (see 'ParamStrict'). Empty token or nil parameter, for example.
+	ErrInvalidParam = fmt.Errorf("Invalid argument")
+)
+
+// 'OpError' is the error of some operation: one of 'Op...' consts.
+//
+// It contains the queried IP addresses (if they are), HTTP status code
+// (if response has been received), the number of attempt and the cause
+// of failure ('Err' field), that might be any error: 'APIErr' object,
+// network error, JSON decoding error or one of predefined 'Err...' errors.
+//
+// NOTE! Requests aren't retried, so 'Attempt' is always 1 if the request
+// has been performed, and 0 if it hasn't (invalid IP address, for example).
+//
+// 'OpError' supports unwrapping, so you can use 'errors.Is' or 'errors.As'
+// to check the cause. For example:
+// "if errors.Is(err, context.DeadlineExceeded) { ... }".
+type OpError struct {
+	Op         string
+	IPs        []string
+	StatusCode int
+	Attempt    int
+	Err        error
+}
+
+// 'Error' implements the 'error' interface for 'OpError' class.
+// It returns the string that represents the operation and its cause
+// by the following pattern:
+// "<op> <ips> (HTTP <status>): <cause>".
+// Parts that have no sense (no IPs, successful HTTP status) are omitted.
+func (e *OpError) Error() string {
+	if e == nil {
+		return ""
+	}
+	s := e.Op
+	if len(e.IPs) > 0 {
+		s += " " + strings.Join(e.IPs, ",")
+	}
+	if e.StatusCode != 0 && e.StatusCode != http.StatusOK {
+		s += fmt.Sprintf(" (HTTP %d)", e.StatusCode)
+	}
+	if e.Err == nil {
+		return s
+	}
+	if s == "" {
+		return e.Err.Error()
+	}
+	return s + ": " + e.Err.Error()
+}
+
+// 'Unwrap' returns the cause of the current error.
+// It's used by 'errors.Is' and 'errors.As'.
+func (e *OpError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Err
+}
@@ -0,0 +1,120 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// 'tTimeoutError' is the network error that is timeout.
+type tTimeoutError struct{}
+
+func (tTimeoutError) Error() string   { return "i/o timeout" }
+func (tTimeoutError) Timeout() bool   { return true }
+func (tTimeoutError) Temporary() bool { return true }
+
+func TestErrorClassification(t *testing.T) {
+	usageLimit := &OpError{Op: OpIP, Err: newAPIErr(CodeUsageLimitReached, "usage_limit_reached", "")}
+	cases := []struct {
+		name                   string
+		err                    error
+		temporary, quota, auth bool
+		planRestriction        bool
+	}{
+		{name: "nil"},
+		{name: "usage limit", err: usageLimit, quota: true},
+		{name: "deadline", err: &OpError{Op: OpIP, Err: context.DeadlineExceeded}, temporary: true},
+		{name: "network timeout", err: &OpError{Op: OpIP, Err: &url.Error{Op: "Get", Err: tTimeoutError{}}}, temporary: true},
+		{name: "refused", err: &OpError{Op: OpIP, Err: &net.OpError{Op: "dial", Err: errors.New("refused")}}},
+		{name: "canceled", err: &OpError{Op: OpIP, Err: context.Canceled}},
+		{name: "auth", err: fmt.Errorf("wrapped: %w", ErrInactiveUser), auth: true},
+		{name: "bulk", err: &OpError{Op: OpIPs, Err: ErrBatchNotSupported}, planRestriction: true},
+	}
+	for _, tc := range cases {
+		if got := IsTemporary(tc.err); got != tc.temporary {
+			t.Errorf("%s: IsTemporary %v", tc.name, got)
+		}
+		if got := IsQuotaExceeded(tc.err); got != tc.quota {
+			t.Errorf("%s: IsQuotaExceeded %v", tc.name, got)
+		}
+		if got := IsAuth(tc.err); got != tc.auth {
+			t.Errorf("%s: IsAuth %v", tc.name, got)
+		}
+		if got := IsPlanRestriction(tc.err); got != tc.planRestriction {
+			t.Errorf("%s: IsPlanRestriction %v", tc.name, got)
+		}
+	}
+	if !errors.Is(usageLimit, ErrUsageLimitReached) {
+		t.Errorf("usage limit error isn't ErrUsageLimitReached")
+	}
+}
+
+func TestOpErrorOfNilArguments(t *testing.T) {
+	c := newFakeClient(t, tFakeTransport(echoJSON))
+	var job *Job
+	_, errProgress := job.Progress()
+	cases := []struct {
+		err      error
+		op       string
+		sentinel error
+	}{
+		{c.StreamFunc(context.Background(), nil, func(StreamResult) error { return nil }), OpStream, ErrNilSource},
+		{c.StreamFunc(context.Background(), strings.NewReader(""), nil), OpStream, ErrNilCallback},
+		{c.Job("cp", nil).Run(context.Background(), strings.NewReader("")), OpJob, ErrNilSink},
+		{c.Job("cp", &tMemorySink{}).Run(context.Background(), nil), OpJob, ErrNilSource},
+		{job.Run(context.Background(), strings.NewReader("")), OpJob, ErrNilJob},
+		{errProgress, OpJob, ErrNilJob},
+	}
+	for i, tc := range cases {
+		var opErr *OpError
+		if !errors.As(tc.err, &opErr) || opErr.Op != tc.op || !errors.Is(tc.err, tc.sentinel) {
+			t.Errorf("case %d: got %v, expected %s operation error %v", i, tc.err, tc.op, tc.sentinel)
+		}
+	}
+}
+
+func TestOpErrorString(t *testing.T) {
+	err := &OpError{Op: OpIPs, IPs: []string{"1.1.1.1", "2.2.2.2"}, StatusCode: 503, Err: ErrNilBody}
+	if want := "IPs 1.1.1.1,2.2.2.2 (HTTP 503): Body of GET response is nil"; err.Error() != want {
+		t.Fatalf("got %q, expected %q", err.Error(), want)
+	}
+}
+
+func TestOpErrorAttempt(t *testing.T) {
+	c := newFakeClient(t, replyWith(usageLimitJSON))
+	var opErr *OpError
+	if _, err := c.IP("1.1.1.1"); !errors.As(err, &opErr) || opErr.Attempt != 1 {
+		t.Fatalf("IP: got %#v, expected the first attempt", err)
+	}
+	// Invalid IP address isn't requested at all
+	if _, err := c.IP("bogus"); !errors.As(err, &opErr) || opErr.Attempt != 0 {
+		t.Fatalf("IP: got %#v, expected no attempts", err)
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"strings"
+)
+
+// 'Field' is the name of some field in JSON response from ipstack.
+// Use predefined consts started with 'Field...' prefix.
+type Field string
+
+// 'FieldSet' is the set of fields you want to get from ipstack Web API.
+// The zero 'FieldSet' object is an empty set, that means "all fields".
+//
+// It's a value type, so all its methods return a new set
+// and never change the current one. Thus you can share predefined
+// sets between the requests.
+//
+// Fields that have children (like 'FieldLocation' or 'FieldCurrency')
+// are expanded: adding the parent adds all its children, and the parent
+// is in the set only while all its children are in the set.
+// So, 'FieldLocation' minus 'FieldLocationCapital' is all other
+// location's fields.
+//
+// Use 'NewFieldSet' to create a set, or 'Fields' method of 'Request' class
+// and 'ParamFields' parameter of 'Client' constructor to pass fields
+// w/o creating a set.
+type FieldSet struct {
+	bits uint64
+}
+
+// 'tFieldNode' is the internal private type that represents the position
+// of some field in the tree of fields.
+type tFieldNode struct {
+	bit      uint64
+	parent   int
+	children []int
+}
+
+// 'fieldTree' is the tree of all predefined fields ('allFields').
+// The parent of "a.b" is "a", and so on.
+// 'fieldIndex' maps the name of field to its index in 'allFields'.
+var fieldTree, fieldIndex = buildFieldTree(allFields)
+
+// 'buildFieldTree' creates the tree of 'fields' using their names.
+// The parents must precede their children in 'fields'.
+func buildFieldTree(fields []Field) ([]tFieldNode, map[Field]int) {
+	if len(fields) > 64 {
+		panic("ipstack: too many fields for FieldSet")
+	}
+	tree := make([]tFieldNode, len(fields))
+	index := make(map[Field]int, len(fields))
+	for i, f := range fields {
+		tree[i] = tFieldNode{bit: 1 << uint(i), parent: -1}
+		index[f] = i
+		if dot := strings.LastIndexByte(string(f), '.'); dot != -1 {
+			p := index[f[:dot]]
+			tree[i].parent = p
+			tree[p].children = append(tree[p].children, i)
+		}
+	}
+	return tree, index
+}
+
+// 'NewFieldSet' creates a new 'FieldSet' object that contains 'fields'
+// (with their children).
+//
+// If some of 'fields' is unknown, the error is returned
+// (wraps 'ErrUnknownField'), and the set contains only known fields.
+func NewFieldSet(fields ...Field) (FieldSet, error) {
+	var (
+		fs      FieldSet
+		unknown []Field
+	)
+	for _, f := range fields {
+		i, ok := fieldIndex[f]
+		if !ok {
+			unknown = append(unknown, f)
+			continue
+		}
+		fs.bits |= expandField(i)
+	}
+	if len(unknown) > 0 {
+		return fs.normalized(), &tUnknownFieldsError{fields: unknown}
+	}
+	return fs.normalized(), nil
+}
+
+// 'allFieldSet' returns the set of all known fields.
+func allFieldSet() FieldSet {
+	return FieldSet{bits: 1<<uint(len(allFields)) - 1}
+}
+
+// 'Union' returns the set of fields that are in the current set
+// or in 'other'.
+func (fs FieldSet) Union(other FieldSet) FieldSet {
+	return FieldSet{bits: fs.bits | other.bits}.normalized()
+}
+
+// 'Intersect' returns the set of fields that are in the current set
+// and in 'other'.
+func (fs FieldSet) Intersect(other FieldSet) FieldSet {
+	return FieldSet{bits: fs.bits & other.bits}.normalized()
+}
+
+// 'Difference' returns the set of fields that are in the current set
+// but not in 'other'.
+func (fs FieldSet) Difference(other FieldSet) FieldSet {
+	return FieldSet{bits: fs.bits &^ other.bits}.normalized()
+}
+
+// 'Has' reports whether 'f' field is in the current set.
+// The parent field is in the set only if all its children are in the set.
+func (fs FieldSet) Has(f Field) bool {
+	i, ok := fieldIndex[f]
+	return ok && fs.bits&fieldTree[i].bit != 0
+}
+
+// 'IsEmpty' reports whether the current set is empty.
+// The empty set means that all fields will be returned by ipstack.
+func (fs FieldSet) IsEmpty() bool {
+	return fs.bits == 0
+}
+
+// 'Fields' returns all fields of the current set (parents and their children
+// are expanded) in the order of their declaration.
+func (fs FieldSet) Fields() []Field {
+	var fields []Field
+	for i, node := range fieldTree {
+		if fs.bits&node.bit != 0 {
+			fields = append(fields, allFields[i])
+		}
+	}
+	return fields
+}
+
+// 'String' renders the current set as the value of "fields" query parameter.
+// If all children of some field are in the set, only that field is rendered.
+// For example: "country_code,location,currency.code".
+func (fs FieldSet) String() string {
+	var parts []string
+	for i, node := range fieldTree {
+		if fs.bits&node.bit == 0 {
+			continue
+		}
+		// Skip the field, if its parent is already rendered
+		if p := node.parent; p != -1 && fs.bits&fieldTree[p].bit != 0 {
+			continue
+		}
+		parts = append(parts, string(allFields[i]))
+	}
+	return strings.Join(parts, ",")
+}
+
+// 'expandField' returns the bits of field with index 'i'
+// and all its descendants.
+func expandField(i int) uint64 {
+	bits := fieldTree[i].bit
+	for _, c := range fieldTree[i].children {
+		bits |= expandField(c)
+	}
+	return bits
+}
+
+// 'normalized' returns the copy of the current set where each parent
+// field is set if and only if all its children are set.
+// Children always follow their parents, so the tree is walked backward.
+func (fs FieldSet) normalized() FieldSet {
+	for i := len(fieldTree) - 1; i >= 0; i-- {
+		node := fieldTree[i]
+		if len(node.children) == 0 {
+			continue
+		}
+		all := true
+		for _, c := range node.children {
+			if fs.bits&fieldTree[c].bit == 0 {
+				all = false
+				break
+			}
+		}
+		if all {
+			fs.bits |= node.bit
+		} else {
+			fs.bits &^= node.bit
+		}
+	}
+	return fs
+}
+
+// 'tUnknownFieldsError' is the internal private type of error that
+// 'NewFieldSet' returns when some passed fields are unknown.
+// It wraps 'ErrUnknownField'.
+type tUnknownFieldsError struct {
+	fields []Field
+}
+
+// 'Error' implements the 'error' interface for 'tUnknownFieldsError' class.
+func (e *tUnknownFieldsError) Error() string {
+	names := make([]string, len(e.fields))
+	for i, f := range e.fields {
+		names[i] = "\"" + string(f) + "\""
+	}
+	return ErrUnknownField.Error() + ": " + strings.Join(names, ", ")
+}
+
+// 'Unwrap' returns 'ErrUnknownField'.
+// It's used by 'errors.Is'.
+func (e *tUnknownFieldsError) Unwrap() error {
+	return ErrUnknownField
+}
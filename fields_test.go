@@ -0,0 +1,147 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// 'mustFieldSet' creates a set of known 'fields'.
+func mustFieldSet(t *testing.T, fields ...Field) FieldSet {
+	t.Helper()
+	fs, err := NewFieldSet(fields...)
+	if err != nil {
+		t.Fatalf("NewFieldSet: %v", err)
+	}
+	return fs
+}
+
+func TestFieldSetAlgebra(t *testing.T) {
+	location := mustFieldSet(t, FieldLocation)
+	languages := mustFieldSet(t, FieldLocationLanguages)
+	capital := mustFieldSet(t, FieldLocationCapital)
+	tests := []struct {
+		name string
+		got  FieldSet
+		want []Field
+	}{
+		{"ParentExpanded", languages, []Field{
+			FieldLocationLanguages, FieldLocationLanguagesCode,
+			FieldLocationLanguagesName, FieldLocationLanguagesNative,
+		}},
+		{"DifferenceWithParent", location.Difference(capital), []Field{
+			FieldLocationGeonameId, FieldLocationLanguages,
+			FieldLocationLanguagesCode, FieldLocationLanguagesName,
+			FieldLocationLanguagesNative, FieldLocationCountryFlag,
+			FieldLocationCountryFlagEmoji, FieldLocationCountryFlagEmojiUnicode,
+			FieldLocationCallingCode, FieldLocationIsEu,
+		}},
+		{"DifferenceOfNested", location.Difference(mustFieldSet(t, FieldLocationLanguagesName)), []Field{
+			FieldLocationGeonameId, FieldLocationCapital,
+			FieldLocationLanguagesCode, FieldLocationLanguagesNative,
+			FieldLocationCountryFlag, FieldLocationCountryFlagEmoji,
+			FieldLocationCountryFlagEmojiUnicode, FieldLocationCallingCode,
+			FieldLocationIsEu,
+		}},
+		{"DifferenceOfParent", location.Difference(location), nil},
+		{"UnionRestoresParent", location.Difference(capital).Union(capital), location.Fields()},
+		{"UnionOfChildren", mustFieldSet(t, FieldLocationLanguagesCode, FieldLocationLanguagesName).
+			Union(mustFieldSet(t, FieldLocationLanguagesNative)), languages.Fields()},
+		{"IntersectWithParent", location.Intersect(mustFieldSet(t, FieldLocationLanguagesCode, FieldCity)), []Field{
+			FieldLocationLanguagesCode,
+		}},
+		{"IntersectParents", location.Intersect(languages), languages.Fields()},
+		{"IntersectDisjoint", location.Intersect(mustFieldSet(t, FieldCurrency)), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.got.Fields(); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Fields: got %v, expected %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldSetHas(t *testing.T) {
+	fs := mustFieldSet(t, FieldLocation).Difference(mustFieldSet(t, FieldLocationCapital))
+	for f, want := range map[Field]bool{
+		FieldLocation:              false,
+		FieldLocationCapital:       false,
+		FieldLocationLanguages:     true,
+		FieldLocationLanguagesCode: true,
+		FieldCity:                  false,
+		"location.unknown":         false,
+	} {
+		if got := fs.Has(f); got != want {
+			t.Errorf("Has(%q): got %v, expected %v", f, got, want)
+		}
+	}
+	if !(FieldSet{}).IsEmpty() || fs.IsEmpty() {
+		t.Errorf("IsEmpty: wrong result")
+	}
+}
+
+func TestFieldSetString(t *testing.T) {
+	tests := []struct {
+		name string
+		fs   FieldSet
+		want string
+	}{
+		{"Empty", FieldSet{}, ""},
+		{"Plain", mustFieldSet(t, FieldCity, FieldCountryCode), "country_code,city"},
+		{"Parent", mustFieldSet(t, FieldCountryCode, FieldLocation, FieldCurrencyCode),
+			"country_code,location,currency.code"},
+		{"AllChildren", mustFieldSet(t, FieldConnectionAsn, FieldConnectionIsp), "connection"},
+		{"WithoutChild", mustFieldSet(t, FieldLocation).Difference(mustFieldSet(t, FieldLocationCapital)),
+			"location.geoname_id,location.languages,location.country_flag," +
+				"location.country_flag_emoji,location.country_flag_emoji_unicode," +
+				"location.calling_code,location.is_eu"},
+		{"NestedChild", mustFieldSet(t, FieldLocationLanguagesName, FieldTimeZone),
+			"location.languages.name,time_zone"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fs.String(); got != tt.want {
+				t.Fatalf("String: got %q, expected %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewFieldSetUnknown(t *testing.T) {
+	fs, err := NewFieldSet(FieldCity, "town", FieldZip, "location.street")
+	if !errors.Is(err, ErrUnknownField) {
+		t.Fatalf("NewFieldSet: got %v, expected ErrUnknownField", err)
+	}
+	want := `Unknown field: "town", "location.street"`
+	if err.Error() != want {
+		t.Fatalf("Error: got %q, expected %q", err.Error(), want)
+	}
+	// Known fields are kept anyway
+	if got := fs.String(); got != "city,zip" {
+		t.Fatalf("String: got %q", got)
+	}
+}
@@ -24,19 +24,20 @@
 package ipstack
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
-// todo: Tests
-// todo: Example
-
 // HOW TO USE THIS LIBRARY?
 //
 // 1. CREATE ENDPOINT
@@ -79,16 +80,16 @@ import (
 // specify only two/three/n fields that you're required and no one bytes more?
 // Or may be I forgot to update this library, API has been changed
 // and you want to manually decode JSON response from ipstack?
-// Just get the 'tRequest' object! Call 'R' method from 'Client'!
+// Just get the 'Request' object! Call 'R' method from 'Client'!
 // Do what you want and perform request by methods with name that
 // you're already know ('IP', 'IPs') or get fresh info about your IP
 // by calling 'Me' method.
-// But be careful, by calling one of these method, you'll get 'tRawResponse'
+// But be careful, by calling one of these method, you'll get 'RawResponse'
 // object that will contain error objects of performed request (if it is),
 // and JSON raw data. Take it and do what you want with it.
 // If you don't want manually check errors (from API by JSON response)
 // and/or manually decode it, just call 'CheckError' and 'DecodeIt' methods
-// of 'tRawResponse' object.
+// of 'RawResponse' object.
 // But you must manually create object to that decode process will peform
 // and pass its reference to the 'DecodeIt' method.
 // 'DecodeIt' always returns an error, and you can chain all that query:
@@ -106,8 +107,6 @@ const (
 	cApiEndpointHTTP string = "http://api.ipstack.com/"
 	// SSL Web API endpoint, disabled by default
 	cApiEndpointHTTPS string = "https://api.ipstack.com/"
-	// security param
-	securityParam string = "security=1"
 )
 
 // 'Client' is the class that represents golang point to make Web API
@@ -119,10 +118,26 @@ const (
 //
 // After 'Client' object created, (and if you don't disable first check)
 // you already have an information about your IP.
+//
+// 'Client' object is safe for concurrent use by multiple goroutines.
+// Its configuration (the base 'Request' object) is never changed after
+// creation, and the cached info about your IP is protected by mutex.
 type Client struct {
-	me              *Response
-	baseReq         *tRequest
+	meMu            sync.Mutex
+	meCache         map[Lang]*Response
+	baseReq         *Request
 	skipInitFetchMe bool
+	asyncWorkers    int
+	asyncQueue      int
+	asyncMu         sync.Mutex
+	asyncClosed     bool
+	asyncSlots      chan struct{}
+	asyncTasks      chan func()
+	// Strict mode of constructor and its state (see 'ParamStrict')
+	strict     bool
+	paramPos   int
+	paramErrs  []error
+	paramsSeen map[string]string
 }
 
 // 'tClientParam' is the internal auxiliary type that is alias to the
@@ -134,26 +149,36 @@ type Client struct {
 // objects and then pass it to the 'New' function ('Client' constructor).
 type tClientParam func(c *Client)
 
-// 'tRequest' is the internal private type that represents one request
+// 'Request' is the type that represents one request
 // to the ipstack Web API.
 //
 // So, object of this class will be created when 'Client' object will being
 // initialize and that object will be marked as 'base request object'.
 // All special Web API params, like GET request params, golang http.Client
-// object, HTTP or HTTPS schema, etc will be stored to 'tRequest' object.
+// object, HTTP or HTTPS schema, etc will be stored to 'Request' object.
 //
 // Read the docs for 'R' method of 'Client' class to understand how
-// 'tRequest' object works and why it exists.
-type tRequest struct {
-	token           string
-	client          *http.Client
-	endpoint        string
-	reqArgs         url.Values
-	reqArgsBuilt    string
-	securityEnabled bool
+// 'Request' object works and why it exists.
+//
+// NOTE! You can store the configured 'Request' object and use it as many
+// times as you want. The set of its methods is stable, so you can hide it
+// behind your own interface (to mock it in tests, for example).
+type Request struct {
+	token        string
+	client       *http.Client
+	ctx          context.Context
+	endpoint     string
+	reqArgs      url.Values
+	reqArgsBuilt string
+	fields       FieldSet
+	lang         Lang
+	format       Format
+	codec        Codec
+	// The first error of configuring the request (see 'validate')
+	err error
 }
 
-// 'tResponse' is the internal private type that represents some RAW
+// 'RawResponse' is the type that represents some RAW
 // response from ipstack to the some Web API request.
 //
 // So, for what it needs?
@@ -162,140 +187,207 @@ type tRequest struct {
 // decode response (JSON) manually.
 //
 // NOTE! If you using custom request using 'R' method of 'Client' class,
-// you will get 'tRequest' object, and methods 'IP', 'IPs', 'Me' of
-// 'tRequest' class always return 'tResponse' object.
+// you will get 'Request' object, and methods 'IP', 'IPs', 'Me' of
+// 'Request' class always return 'RawResponse' object.
 // It means, that you must take care of error analysing and JSON decoding.
 //
 // NOTE! It guarantees, that if 'RequestError' isn't nil, 'ResponseError' and
 // 'RawData' are. Similar, if 'ResponseError' isn't nil, 'RawData' is.
-type tResponse struct {
+//
+// NOTE! 'Error' field is always the 'OpError' object (if it isn't nil).
+type RawResponse struct {
 	RawData []byte
 	Error   error
+	op      string
+	ips     []string
+	status  int
+	attempt int
+	lang    Lang
+	format  Format
+	codec   Codec
 }
 
 // 'Response' represents the golang view of Web API response.
 // Fields 'Location', 'Timezone', 'Currency', 'Connection', 'Security'
 // might be nil, if you didn't request it earlier using 'Fields' method
-// of 'Client' or 'tRequest' classes, or 'ParamFields' parameter of 'Client'
+// of 'Client' or 'Request' classes, or 'ParamFields' parameter of 'Client'
 // constructor ('New' function).
 //
+// Fields 'Latitide' and 'Longitude' are 'float32' and kept only
+// for compatibility. Use 'Lat', 'Lon' or 'LatLon' methods to get
+// coordinates with full precision.
+//
+// A zero value of field might be a real value (latitude 0 is a real place)
+// or the field might be absent (not requested or not available on your plan).
+// Use 'Has' method to distinguish them.
+//
+// The original JSON response and its undeclared fields are kept in 'Raw'
+// and 'Extra' fields, so encoding 'Response' object back to JSON
+// doesn't lose anything (see 'MarshalJSON').
+//
 // NOTE! If you do not understand what data stored in field,
 // read the docs of the consts 'Field...'  (above).
 type Response struct {
-	IP            string               `json:"ip"`
-	Hostname      string               `json:"hostname"`
-	Type          string               `json:"type"`
-	ContinentCode string               `json:"continent_code"`
-	ContinentName string               `json:"continent_name"`
-	CountryCode   string               `json:"country_code"`
-	CountryName   string               `json:"country_name"`
-	RegionCode    string               `json:"region_code"`
-	RegionName    string               `json:"region_name"`
-	City          string               `json:"city"`
-	Zip           string               `json:"zip"`
-	Latitide      float32              `json:"latitude"`
-	Longitude     float32              `json:"longitude"`
-	Location      *tResponseLoc        `json:"location"`
-	Timezone      *tResponseTimeZone   `json:"time_zone"`
-	Currency      *tResponseCurrency   `json:"currency"`
-	Connection    *tResponseConnection `json:"connection"`
-	Security      *tResponseSecurity   `json:"security"`
-}
-
-// 'tResponseLoc' is the part of Web API response and represents
+	IP            string      `json:"ip" xml:"ip"`
+	Hostname      string      `json:"hostname" xml:"hostname"`
+	Type          string      `json:"type" xml:"type"`
+	ContinentCode string      `json:"continent_code" xml:"continent_code"`
+	ContinentName string      `json:"continent_name" xml:"continent_name"`
+	CountryCode   string      `json:"country_code" xml:"country_code"`
+	CountryName   string      `json:"country_name" xml:"country_name"`
+	RegionCode    string      `json:"region_code" xml:"region_code"`
+	RegionName    string      `json:"region_name" xml:"region_name"`
+	City          string      `json:"city" xml:"city"`
+	Zip           string      `json:"zip" xml:"zip"`
+	Latitide      float32     `json:"latitude" xml:"latitude"`
+	Longitude     float32     `json:"longitude" xml:"longitude"`
+	Location      *Location   `json:"location" xml:"location"`
+	Timezone      *TimeZone   `json:"time_zone" xml:"time_zone"`
+	Currency      *Currency   `json:"currency" xml:"currency"`
+	Connection    *Connection `json:"connection" xml:"connection"`
+	Security      *Security   `json:"security" xml:"security"`
+
+	// The original JSON object of the response as it has been received.
+	// It's filled by 'UnmarshalJSON' and isn't encoded as is.
+	Raw json.RawMessage `json:"-" xml:"-"`
+	// The fields of JSON response that aren't declared above
+	// (ipstack might add new fields), by their names.
+	// They are encoded back by 'MarshalJSON'.
+	Extra map[string]json.RawMessage `json:"-" xml:"-"`
+
+	// The zone of requested IP address (if it has been passed as
+	// 'netip.Addr' object). It isn't part of Web API response.
+	zone string
+	// The bits of fields that are in JSON response (see 'Has').
+	present uint64
+	// Full precision coordinates from JSON response (see 'Lat', 'Lon').
+	lat, lon float64
+	// The language of response (see 'Language').
+	lang Lang
+}
+
+// 'Location' is the part of Web API response and represents
 // the location info about requested IP.
 //
 // NOTE! If you do not understand what data stored in field,
 // read the docs of the consts 'Field...'  (above).
-type tResponseLoc struct {
-	GeonameID               int                `json:"geoname_id"`
-	Capital                 string             `json:"capital"`
-	Languages               []tResponseLocLang `json:"languages"`
-	CountryFlagLink         string             `json:"country_flag"`
-	CountryFlagEmoji        string             `json:"country_flag_emoji"`
-	CountryFlagEmojiUnicode string             `json:"country_flag_emoji_unicode"`
-	CallingCode             string             `json:"calling_code"`
-	IsEU                    bool               `json:"is_eu"`
-}
-
-// 'tResponseLocLang' is the part of Web API response and represents
+type Location struct {
+	GeonameID               int        `json:"geoname_id" xml:"geoname_id"`
+	Capital                 string     `json:"capital" xml:"capital"`
+	Languages               []Language `json:"languages" xml:"languages"`
+	CountryFlagLink         string     `json:"country_flag" xml:"country_flag"`
+	CountryFlagEmoji        string     `json:"country_flag_emoji" xml:"country_flag_emoji"`
+	CountryFlagEmojiUnicode string     `json:"country_flag_emoji_unicode" xml:"country_flag_emoji_unicode"`
+	CallingCode             string     `json:"calling_code" xml:"calling_code"`
+	IsEU                    bool       `json:"is_eu" xml:"is_eu"`
+}
+
+// 'Language' is the part of Web API response and represents
 // the info about languages in the location of requested IP.
 //
 // NOTE! If you do not understand what data stored in field,
 // read the docs of the consts 'Field...'  (above).
-type tResponseLocLang struct {
-	Code       string `json:"code"`
-	Name       string `json:"name"`
-	NativeName string `json:"native"`
+type Language struct {
+	Code       string `json:"code" xml:"code"`
+	Name       string `json:"name" xml:"name"`
+	NativeName string `json:"native" xml:"native"`
 }
 
-// 'tResponseTimeZone' is the part of Web API response and represents
+// 'TimeZone' is the part of Web API response and represents
 // the info about time zone info in the location of requested IP.
 //
 // NOTE! If you do not understand what data stored in field,
 // read the docs of the consts 'Field...'  (above).
-type tResponseTimeZone struct {
-	ID               string    `json:"id"`
-	CurrentTime      time.Time `json:"current_time"`
-	GMTOffset        int       `json:"gmt_offset"`
-	Code             string    `json:"code"`
-	IsDaylightSaving bool      `json:"is_daylight_saving"`
+type TimeZone struct {
+	ID               string    `json:"id" xml:"id"`
+	CurrentTime      time.Time `json:"current_time" xml:"current_time"`
+	GMTOffset        int       `json:"gmt_offset" xml:"gmt_offset"`
+	Code             string    `json:"code" xml:"code"`
+	IsDaylightSaving bool      `json:"is_daylight_saving" xml:"is_daylight_saving"`
 }
 
-// 'tResponseCurrency' is the part of Web API response and represents
+// 'Currency' is the part of Web API response and represents
 // the info about main currency in the location of requested IP.
 //
 // NOTE! If you do not understand what data stored in field,
 // read the docs of the consts 'Field...'  (above).
-type tResponseCurrency struct {
-	Code         string `json:"code"`
-	Name         string `json:"name"`
-	Plural       string `json:"plural"`
-	Symbol       string `json:"symbol"`
-	SymbolNative string `json:"symbol_native"`
+type Currency struct {
+	Code         string `json:"code" xml:"code"`
+	Name         string `json:"name" xml:"name"`
+	Plural       string `json:"plural" xml:"plural"`
+	Symbol       string `json:"symbol" xml:"symbol"`
+	SymbolNative string `json:"symbol_native" xml:"symbol_native"`
 }
 
-// 'tResponseConnection' is the part of Web API response and represents...
-// I dunno what is this part represents.
-// If anyone read this section comment and knows, please add info about it.
-// PR is welcome!
-type tResponseConnection struct {
-	ASN int    `json:"asn"`
-	ISP string `json:"isp"`
+// 'Connection' is the part of Web API response and represents
+// the info about the network requested IP belongs to: the Autonomous System
+// Number and the name of the Internet Service Provider.
+//
+// NOTE! If you do not understand what data stored in field,
+// read the docs of the consts 'Field...'  (above).
+type Connection struct {
+	ASN int    `json:"asn" xml:"asn"`
+	ISP string `json:"isp" xml:"isp"`
 }
 
-// 'tResponseSecurity' is the part of Web API response and represents
+// 'Security' is the part of Web API response and represents
 // some other info about requested IP.
 //
 // NOTE! If you do not understand what data stored in field,
 // read the docs of the consts 'Field...'  (above).
-type tResponseSecurity struct {
-	IsProxy     bool        `json:"is_proxy"`
-	ProxyType   string      `json:"proxy_type"`
-	IsCrawler   bool        `json:"is_crawler"`
-	CrawlerName string      `json:"crawler_name"`
-	CrawlerType string      `json:"crawler_type"`
-	IsTOR       bool        `json:"is_tor"`
-	ThreatLevel string      `json:"threat_level"`
-	ThreatTypes interface{} `json:"threat_types"`
+//
+// Fields 'ProxyType', 'CrawlerType', 'ThreatLevel' and 'ThreatTypes'
+// are typed. Compare them with the consts 'Proxy...', 'Crawler...',
+// 'ThreatLevel...' and 'Threat...' (see security.go).
+type Security struct {
+	IsProxy     bool        `json:"is_proxy" xml:"is_proxy"`
+	ProxyType   ProxyType   `json:"proxy_type" xml:"proxy_type"`
+	IsCrawler   bool        `json:"is_crawler" xml:"is_crawler"`
+	CrawlerName string      `json:"crawler_name" xml:"crawler_name"`
+	CrawlerType CrawlerType `json:"crawler_type" xml:"crawler_type"`
+	IsTOR       bool        `json:"is_tor" xml:"is_tor"`
+	ThreatLevel ThreatLevel `json:"threat_level" xml:"threat_level"`
+	ThreatTypes ThreatTypes `json:"threat_types" xml:"threat_types"`
 }
 
+// Old names of the request, raw response and API error types.
+// They were used when these types were unexported and kept only
+// for compatibility. Use 'Request', 'RawResponse' and 'APIErr' instead.
+type (
+	tRequest  = Request
+	tResponse = RawResponse
+	tError    = APIErr
+)
+
+// Old names of the parts of Web API response.
+// They were used when these types were unexported and kept only
+// for compatibility. Use 'Location', 'Language', 'TimeZone', 'Currency',
+// 'Connection' and 'Security' instead.
+type (
+	tResponseLoc        = Location
+	tResponseLocLang    = Language
+	tResponseTimeZone   = TimeZone
+	tResponseCurrency   = Currency
+	tResponseConnection = Connection
+	tResponseSecurity   = Security
+)
+
 // 'tResponseError' is the internal auxiliary type that exists only for
 // represents the parts of JSON encoded response from Web API.
 //
 // Web API error JSON message looks like:
 // "{ "success": false, error: { "code": N, "type": "...", "info": "..." } }".
-// The 'code', 'type' and 'info' fields are explains in 'tError' class docs.
+// The 'code', 'type' and 'info' fields are explains in 'APIErr' class docs.
 type tResponseError struct {
 	Success bool   `json:"success"`
-	Error   tError `json:"error"`
+	Error   APIErr `json:"error"`
 }
 
-// 'tError' represents the Web API error message.
+// 'APIErr' represents the Web API error message.
 // Be careful, object of that class might be created only if API request
 // has been successfully sent and SOME response has been successfully received.
 // And if that response contains error message (that consists of these entities)
-// the 'tError' object will be created.
+// the 'APIErr' object will be created.
 //
 // Error entities:
 // <code> - is the error code of Web API and the the fastest way
@@ -305,17 +397,21 @@ type tResponseError struct {
 // <info> - is the description of error - a long explanation about
 // occurred error - what happened and why.
 //
-// You can get that error object when you calling 'IP', 'IPs', 'UpdateMe'
-// methods as the second return argument, or as field 'ResponseError'
-// in 'tRawResponse' object, if you're working with RAW response object
-// (often it might be only if you're working with RAW request object
-// using 'R' method of 'Client' class).
+// You can get that error object when you calling 'IP', 'IPs', 'Me'
+// methods as the cause of the second return argument ('OpError' object),
+// or as the cause of field 'Error' in 'RawResponse' object, if you're working
+// with RAW response object (often it might be only if you're working with
+// RAW request object using 'R' method of 'Client' class).
+// Use 'APIError' function or 'errors.As' to get it.
 //
 // See 'tResponseError' docs for more details.
-type tError struct {
-	RawCode int    `json:"code"`
-	RawType string `json:"type"`
-	RawInfo string `json:"info"`
+//
+// NOTE! It's the named type, so you can use it as target of 'errors.As':
+// "var apiErr *ipstack.APIErr; if errors.As(err, &apiErr) { ... }".
+type APIErr struct {
+	RawCode int    `json:"code" xml:"code"`
+	RawType string `json:"type" xml:"type"`
+	RawInfo string `json:"info" xml:"info"`
 }
 
 // Predefined consts each of that represents some field in JSON response
@@ -323,127 +419,130 @@ type tError struct {
 //
 // So, you can read on https://ipstack.com/documentation that
 // you can specify what fields should be returned as response.
-// Using that consts and 'Fields' method of 'Client' or 'tRequest' classes
-// you can do it.
+// Using that consts and 'Fields' method of 'Request' class
+// or 'ParamFields' parameter of 'Client' constructor you can do it.
+//
+// Fields that have children (like 'FieldLocation') mean all their children.
+// See 'FieldSet' docs for details.
 const (
 	// Returns the requested IP address.
-	FieldIp string = "ip"
+	FieldIp Field = "ip"
 	// Returns the hostname the requested IP resolves to,
 	// only returned if Hostname Lookup is enabled.
-	FieldHostname string = "hostname"
+	FieldHostname Field = "hostname"
 	// Returns the IP address type IPv4 or IPv6.
-	FieldType string = "type"
+	FieldType Field = "type"
 	// Returns the 2-letter continent code associated with the IP.
-	FieldContinentCode string = "continent_code"
+	FieldContinentCode Field = "continent_code"
 	// Returns the name of the continent associated with the IP.
-	FieldContinentName string = "continent_name"
+	FieldContinentName Field = "continent_name"
 	// Returns the 2-letter country code associated with the IP.
-	FieldCountryCode string = "country_code"
+	FieldCountryCode Field = "country_code"
 	// Returns the name of the country associated with the IP.
-	FieldCountryName string = "country_name"
+	FieldCountryName Field = "country_name"
 	// Returns the region code of the region associated with the IP
 	// (e.g. CA for California).
-	FieldRegionCode string = "region_code"
+	FieldRegionCode Field = "region_code"
 	// Returns the name of the region associated with the IP.
-	FieldRegionName string = "region_name"
+	FieldRegionName Field = "region_name"
 	// Returns the name of the city associated with the IP.
-	FieldCity string = "city"
+	FieldCity Field = "city"
 	// Returns the ZIP code associated with the IP.
-	FieldZip string = "zip"
+	FieldZip Field = "zip"
 	// Returns the latitude value associated with the IP.
-	FieldLatitude string = "latitude"
+	FieldLatitude Field = "latitude"
 	// Returns the longitude value associated with the IP.
-	FieldLongitude string = "longitude"
+	FieldLongitude Field = "longitude"
 	// Returns multiple location-related objects
-	FieldLocation string = "location"
+	FieldLocation Field = "location"
 	// Returns the unique geoname identifier
 	// in accordance with the Geonames Registry.z
-	FieldLocationGeonameId string = "location.geoname_id"
+	FieldLocationGeonameId Field = "location.geoname_id"
 	// Returns the capital city of the country associated with the IP.
-	FieldLocationCapital string = "location.capital"
+	FieldLocationCapital Field = "location.capital"
 	// Returns an object containing one or multiple
 	// sub-objects per language spoken in the country associated with the IP.
-	FieldLocationLanguages string = "location.languages"
+	FieldLocationLanguages Field = "location.languages"
 	// Returns the 2-letter language code for the given language.
-	FieldLocationLanguagesCode string = "location.languages.code"
+	FieldLocationLanguagesCode Field = "location.languages.code"
 	// Returns the name (in the API request's main language)
 	// of the given language. (e.g. Portuguese)
-	FieldLocationLanguagesName string = "location.languages.name"
+	FieldLocationLanguagesName Field = "location.languages.name"
 	// Returns the native name of the given language. (e.g. Português)
-	FieldLocationLanguagesNative string = "location.languages.native"
+	FieldLocationLanguagesNative Field = "location.languages.native"
 	// Returns an HTTP URL leading to an SVG-flag icon for the country
 	// associated with the IP.
-	FieldLocationCountryFlag string = "location.country_flag"
+	FieldLocationCountryFlag Field = "location.country_flag"
 	// Returns the emoji icon for the flag of the country associated with the IP.
-	FieldLocationCountryFlagEmoji string = "location.country_flag_emoji"
+	FieldLocationCountryFlagEmoji Field = "location.country_flag_emoji"
 	// Returns the unicode value of the emoji icon for the flag of the country
 	// associated with the IP. (e.g. U+1F1F5 U+1F1F9 for the Portuguese flag)
-	FieldLocationCountryFlagEmojiUnicode string = "location.country_flag_emoji_unicode"
+	FieldLocationCountryFlagEmojiUnicode Field = "location.country_flag_emoji_unicode"
 	// Returns the calling/dial code of the country associated with the IP.
 	// (e.g. 351) for Portugal.
-	FieldLocationCallingCode string = "location.calling_code"
+	FieldLocationCallingCode Field = "location.calling_code"
 	// Returns true or false depending on whether or not the county
 	// associated with the IP is in the European Union.
-	FieldLocationIsEu string = "location.is_eu"
+	FieldLocationIsEu Field = "location.is_eu"
 	// Returns an object containing timezone-related data.
-	FieldTimeZone string = "timezone"
+	FieldTimeZone Field = "time_zone"
 	// Returns the ID of the time zone associated with the IP.
 	// (e.g. America/LosAngeles for PST)
-	FieldTimeZoneId string = "timezone.id"
+	FieldTimeZoneId Field = "time_zone.id"
 	// Returns the current date and time in the location
 	// associated with the IP. (e.g. 2018-03-29T22:31:27-07:00)
-	FieldTimeZoneCurrentTime string = "timezone.current_time"
+	FieldTimeZoneCurrentTime Field = "time_zone.current_time"
 	// Returns the GMT offset of the given time zone in seconds.
 	// (e.g. -25200 for PST's -7h GMT offset)
-	FieldTimeZoneGmtOffset string = "timezone.gmt_offset"
+	FieldTimeZoneGmtOffset Field = "time_zone.gmt_offset"
 	// Returns the universal code of the given time zone.
-	FieldTimeZoneCode string = "timezone.code"
+	FieldTimeZoneCode Field = "time_zone.code"
 	// Returns true or false depending on whether or not the given time zone
 	// is considered daylight saving time.
-	FieldTimeZoneIsDaylightSaving string = "timezone.is_daylight_saving"
+	FieldTimeZoneIsDaylightSaving Field = "time_zone.is_daylight_saving"
 	// Returns an object containing currency-related data.
-	FieldCurrency string = "currency"
+	FieldCurrency Field = "currency"
 	// Returns the 3-letter code of the main currency associated with the IP.
-	FieldCurrencyCode string = "currency.code"
+	FieldCurrencyCode Field = "currency.code"
 	// Returns the name of the given currency.
-	FieldCurrencyName string = "currency.mame"
+	FieldCurrencyName Field = "currency.name"
 	// Returns the plural name of the given currency.
-	FieldCurrencyPlural string = "currency.plural"
+	FieldCurrencyPlural Field = "currency.plural"
 	// Returns the symbol letter of the given currency.
-	FieldCurrencySymbol string = "currency.symbol"
+	FieldCurrencySymbol Field = "currency.symbol"
 	// Returns the native symbol letter of the given currency.
-	FieldCurrencySymbolNative string = "currency.symbol_native"
+	FieldCurrencySymbolNative Field = "currency.symbol_native"
 	// Returns an object containing connection-related data.
-	FieldConnection string = "connection"
+	FieldConnection Field = "connection"
 	// Returns the Autonomous System Number associated with the IP.
-	FieldConnectionAsn string = "connection.asn"
+	FieldConnectionAsn Field = "connection.asn"
 	// Returns the name of the ISP associated with the IP.
-	FieldConnectionIsp string = "connection.isp"
+	FieldConnectionIsp Field = "connection.isp"
 	// Returns an object containing security-related data.
-	FieldSecurity string = "security"
+	FieldSecurity Field = "security"
 	// Returns true or false depending on whether or not the given IP
 	// is associated with a proxy.
-	FieldSecurityIsProxy string = "security.is_proxy"
+	FieldSecurityIsProxy Field = "security.is_proxy"
 	// Returns the type of proxy the IP is associated with.
-	FieldSecurityProxyType string = "security.proxy_type"
+	FieldSecurityProxyType Field = "security.proxy_type"
 	// Returns true or false depending on whether or not the given IP
 	// is associated with a crawler.
-	FieldSecurityIsCrawler string = "security.is_crawler"
+	FieldSecurityIsCrawler Field = "security.is_crawler"
 	// Returns the name of the crawler the IP is associated with.
-	FieldSecurityCrawlerName string = "security.crawler_name"
+	FieldSecurityCrawlerName Field = "security.crawler_name"
 	// Returns the type of crawler the IP is associated with.
-	FieldSecurityCrawlerType string = "security.crawler_type"
+	FieldSecurityCrawlerType Field = "security.crawler_type"
 	// Returns true or false depending on whether or not the given IP
 	// is associated with the anonymous Tor system.
-	FieldSecurityIsTor string = "security.is_tor"
+	FieldSecurityIsTor Field = "security.is_tor"
 	// Returns the type of threat level the IP is associated with.
-	FieldSecurityThreatLevel string = "security.threat_level"
+	FieldSecurityThreatLevel Field = "security.threat_level"
 	// Returns an object containing all threat types associated with the IP.
-	FieldSecurityThreatTypes string = "security.threat_types"
+	FieldSecurityThreatTypes Field = "security.threat_types"
 )
 
 // The slice of all predefined field name's consts.
-var allFields = []string{
+var allFields = []Field{
 	// General
 	FieldIp, FieldHostname, FieldType,
 	FieldContinentCode, FieldContinentName,
@@ -480,13 +579,57 @@ var allFields = []string{
 // or when you will call 'New' function first time and that call will be
 // successfull, the 'New' function will also tagged the created client
 // as default client (will store pointer to the 'DefaultClient').
+//
+// Deprecated: Reading and writing this variable isn't safe for concurrent
+// use. Use 'Default' and 'SetDefault' functions instead.
+//
+// WARNING! It's the write-only legacy alias: the package updates it
+// along with the default client, but never reads it. Thus, the assigning
+// to this variable doesn't change the default client used
+// by package level functions.
 var DefaultClient *Client
 
+// 'defaultMu' protects 'defaultClient' (and writes of 'DefaultClient').
+// 'defaultClient' is the default client set by 'SetDefault'.
+var (
+	defaultMu     sync.RWMutex
+	defaultClient *Client
+)
+
+// 'Default' returns the default client, that is used by package level
+// functions ('IP', 'IPs', 'Me', etc), or nil if it isn't initialized.
+// It's safe for concurrent use.
+func Default() *Client {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultClient
+}
+
+// 'SetDefault' replaces the default client by 'c'
+// (see 'Default' and 'Init' docs). It's safe for concurrent use.
+func SetDefault(c *Client) {
+	defaultMu.Lock()
+	defaultClient = c
+	DefaultClient = c
+	defaultMu.Unlock()
+}
+
+// 'setDefaultIfNil' sets the default client to 'c' only if it isn't
+// initialized yet. Check and set are performed atomically.
+func setDefaultIfNil(c *Client) {
+	defaultMu.Lock()
+	if defaultClient == nil {
+		defaultClient = c
+		DefaultClient = c
+	}
+	defaultMu.Unlock()
+}
+
 // 'R' is the way to the improve your flexibility!
-// 'R' returns the 'tRequest' object - object of special type, that contains
+// 'R' returns the 'Request' object - object of special type, that contains
 // in itself all important data to perform Web API request, and,
 // that most importantly, have some methods to change its behaviour!
-// See docs for 'tRequest' object and see 'tRequest' methods.
+// See docs for 'Request' object and see 'Request' methods.
 //
 // NOTE! Yes, it's very simple. You can just call 'R' method of your
 // client object, or call 'R' package level function to get package level
@@ -504,13 +647,13 @@ var DefaultClient *Client
 // _, _ := c.R().<method1>(...).<method2>(...).<method3>(...).IP(1.2.3.4)
 //
 // WARNING!
-// All finishers of 'tRequest' object returns 'tRawResponse' object!
+// All finishers of 'Request' object returns 'RawResponse' object!
 // It means, that you need manually check if any error is occur and
 // manually decode the raw JSON response.
-// BUT! You can use 'CheckError' and 'DecodeTo' methods of 'tRawResponse'
+// BUT! You can use 'CheckError' and 'DecodeTo' methods of 'RawResponse'
 // class.
 // In truth, the 'IP', 'IPs' and 'UpdateMe' methods of 'Client' works that way.
-func (c *Client) R() *tRequest {
+func (c *Client) R() *Request {
 	if err := c.validate(); err != nil {
 		return nil
 	}
@@ -521,20 +664,9 @@ func (c *Client) R() *tRequest {
 // If any error occur, the second return argument will contain error object.
 func (c *Client) IP(ip string) (*Response, error) {
 	if err := c.validate(); err != nil {
-		return nil, err
-	}
-	// Save raw response object, check request error
-	rr := c.baseReq.IP(ip)
-	// Check whether API return an error as encoded JSON
-	if err := rr.CheckError(); err != nil {
-		return nil, err
+		return nil, &OpError{Op: OpIP, IPs: []string{ip}, Err: err}
 	}
-	// Try to decode encoded JSON as 'Response' object, check error
-	r := Response{}
-	if err := rr.DecodeTo(&r); err != nil {
-		return nil, err
-	}
-	return &r, nil
+	return decodeOne(c.baseReq.IP(ip))
 }
 
 // 'IPs' returns the info about each requested IP addresses (you can pass
@@ -543,57 +675,116 @@ func (c *Client) IP(ip string) (*Response, error) {
 // If any error occur, the second argument will contain error object.
 func (c *Client) IPs(ips ...string) ([]*Response, error) {
 	if err := c.validate(); err != nil {
-		return nil, err
+		return nil, &OpError{Op: OpIPs, IPs: ips, Err: err}
 	}
-	// Save raw response object, check request error
-	rr := c.baseReq.IPs(ips...)
-	// Check whether API return an error as encoded JSON
-	if err := rr.CheckError(); err != nil {
+	return decodeMany(c.baseReq.IPs(ips...))
+}
+
+// 'NetIP' is the same as 'IP' but takes golang 'net.IP' object,
+// so it doesn't need to be formatted and parsed again.
+func (c *Client) NetIP(ip net.IP) (*Response, error) {
+	if err := c.validate(); err != nil {
+		return nil, &OpError{Op: OpIP, IPs: []string{ip.String()}, Err: err}
+	}
+	return decodeOne(c.baseReq.NetIP(ip))
+}
+
+// 'NetIPs' is the same as 'IPs' but takes golang 'net.IP' objects,
+// so they don't need to be formatted and parsed again.
+func (c *Client) NetIPs(ips ...net.IP) ([]*Response, error) {
+	if err := c.validate(); err != nil {
+		return nil, &OpError{Op: OpIPs, IPs: netIPStrings(ips), Err: err}
+	}
+	return decodeMany(c.baseReq.NetIPs(ips...))
+}
+
+// 'decodeOne' is the internal private auxiliary function for 'Client'
+// methods that returns info about one IP address.
+// It checks whether API return an error as encoded JSON in 'rr'
+// and then tries to decode encoded JSON as 'Response' object.
+func decodeOne(rr *RawResponse) (*Response, error) {
+	r := Response{}
+	if err := rr.decode(&r); err != nil {
 		return nil, err
 	}
-	// Try to decode encoded JSON as 'Response' object, check error
+	return &r, nil
+}
+
+// 'decodeMany' is the same as 'decodeOne' but for 'Client' methods
+// that returns info about a few IP addresses.
+func decodeMany(rr *RawResponse) ([]*Response, error) {
 	r := []*Response{}
-	if err := rr.DecodeTo(&r); err != nil {
+	if err := rr.decode(&r); err != nil {
 		return nil, err
 	}
 	return r, nil
 }
 
-// 'Me' fetches the fresh info about your IP address and if this
-// operation was successfull, store it as 'Me' field of the current object.
-// todo: fix comment
+// 'Me' returns the info about your IP address.
+// The info is fetched only once (by constructor, if the first query isn't
+// disabled, or by the first call) and cached by the current object,
+// so next calls return the cached object w/o performing request.
+// Pass true as 'forceFetch' to fetch the fresh info and update the cache.
+//
+// NOTE! The cache is safe for concurrent use. The cached object is never
+// changed, fresh info is always decoded to the new object.
+// If fetching of fresh info fails, the cached object (if it is) is returned
+// along with the error.
+//
+// NOTE! The info is cached per language, so use 'MeIn' to get it
+// in the other language than the language of the current object.
 func (c *Client) Me(forceFetch ...bool) (*Response, error) {
 	if err := c.validate(); err != nil {
-		return nil, err
+		return nil, &OpError{Op: OpMe, Err: err}
 	}
 	// Should we fetch fresh info, or return cached data
-	ff := len(forceFetch) > 0 && forceFetch[0]
+	return c.me(c.baseReq, len(forceFetch) > 0 && forceFetch[0])
+}
+
+// 'me' is the internal private auxiliary method of 'Client' class.
+// It returns the cached info about your IP address in the language of 'req'
+// (if it is and 'forceFetch' is false), or fetches it using 'req'
+// and updates the cache.
+func (c *Client) me(req *Request, forceFetch bool) (*Response, error) {
 	// Return cached data if it is and if fresh fetch do not requested
-	if c.me != nil && !ff {
-		return c.me, nil
-	}
-	// Fetch fresh data, check request error
-	rr := c.baseReq.Me()
-	// Check whether API return an error as encoded JSON
-	if err := rr.CheckError(); err != nil {
-		return c.me, err
-	}
-	// Efficency determine where response will store.
-	// If 'me' field isn't nil already, decode response to that field.
-	// In another way, create a variable to that decode operation will,
-	// and in the future, if decode will success, we'll save it to 'me' field.
-	r := c.me
-	if r == nil {
-		r = &Response{}
-	}
-	// Try to decode encoded JSON as 'Response' object, check error
-	if err := rr.DecodeTo(&r); err != nil {
-		return c.me, err
+	key := meCacheKey(req.lang)
+	me := c.cachedMe(key)
+	if me != nil && !forceFetch {
+		return me, nil
+	}
+	// Fetch fresh data, check error and decode it to the new object.
+	// Cached object is never changed, because it might be used
+	// by other goroutines right now.
+	r := &Response{}
+	if err := req.Me().decode(r); err != nil {
+		return me, err
 	}
 	// If this code point is reached, there's no error of JSON decoding
-	// and we can safely save 'r' to 'me' field and return it of course.
-	c.me = r
-	return c.me, nil
+	// and we can safely save 'r' to the cache and return it of course.
+	c.meMu.Lock()
+	if c.meCache == nil {
+		c.meCache = make(map[Lang]*Response)
+	}
+	c.meCache[key] = r
+	c.meMu.Unlock()
+	return r, nil
+}
+
+// 'cachedMe' returns the cached info about your IP address
+// in the language 'key' (or nil).
+func (c *Client) cachedMe(key Lang) *Response {
+	c.meMu.Lock()
+	defer c.meMu.Unlock()
+	return c.meCache[key]
+}
+
+// 'meCacheKey' returns the key of the cached info about your IP address
+// in the language 'lang'. The language isn't specified means English.
+func meCacheKey(lang Lang) Lang {
+	if lang == "" {
+		return LangEnglish
+	}
+	return lang
 }
 
 // 'validate' is the auxiliary method for all public 'Client' methods.
@@ -605,17 +796,17 @@ func (c *Client) Me(forceFetch ...bool) (*Response, error) {
 // method error probably will be returned by the caller too.
 //
 // Errors and its reasons:
-// "Nil client object": You tries to call some method of the nil object
+// 'ErrNilClient': You tries to call some method of the nil object
 // of client. For example: (*Client)(nil).<some_method>(...).
-// "Incorrect internal state": Probably you create 'Client' object directly
+// 'ErrInvalidClient': Probably you create 'Client' object directly
 // and now you tries to call some method of that object. It's not allowed.
 // Use 'New' constructor instead to create a 'Client' instance.
 func (c *Client) validate() error {
 	if c == nil {
-		return fmt.Errorf("Nil client object")
+		return ErrNilClient
 	}
 	if c.baseReq == nil {
-		return fmt.Errorf("Incorrect internal state")
+		return ErrInvalidClient
 	}
 	return nil
 }
@@ -624,19 +815,52 @@ func (c *Client) validate() error {
 // 'tClientParam' objects in 'params' slice, and if they're found, then tries
 // to apply each of them to the current client.
 //
+// It also checks other arguments (token, golang HTTP client) and saves
+// all found misconfigurations (see 'paramError'), but they're reported
+// only in strict mode (see 'ParamStrict').
+//
 // This method calling in 'Client' constructor ('New' function)
 // as part of 'Client' initialize process.
 func (c *Client) applyParams(params []interface{}) {
-	for _, param := range params {
-		if param == nil {
-			continue
-		}
-		clientParam, ok := param.(tClientParam)
-		if !ok || clientParam == nil {
-			continue
+	for i, param := range params {
+		c.paramPos = i + 1
+		switch v := param.(type) {
+		case nil:
+			c.paramError(fmt.Errorf("%w: nil", ErrInvalidParam))
+		case tClientParam:
+			if v == nil {
+				c.paramError(fmt.Errorf("%w: nil parameter", ErrInvalidParam))
+				continue
+			}
+			// Each invalid value saved to the base request must be reported,
+			// not only the first one, but the first one is kept as is
+			// for non-strict mode
+			prevErr := c.baseReq.err
+			c.baseReq.err = nil
+			v(c)
+			if c.baseReq.err != nil {
+				c.paramError(c.baseReq.err)
+			}
+			if prevErr != nil || c.baseReq.err == nil {
+				c.baseReq.err = prevErr
+			}
+		case string:
+			c.checkTokenArg(v)
+		case []byte:
+			c.checkTokenArg(string(v))
+		case http.Client:
+			c.noteParam("http client", fmt.Sprintf("value #%d", c.paramPos))
+		case *http.Client:
+			if v == nil {
+				c.paramError(fmt.Errorf("%w: nil *http.Client", ErrInvalidParam))
+				continue
+			}
+			c.noteParam("http client", fmt.Sprintf("%p", v))
+		default:
+			c.paramError(fmt.Errorf("%w of type %T", ErrUnknownParam, param))
 		}
-		clientParam(c)
 	}
+	c.paramPos = 0
 }
 
 // 'UseHTTPS' changes the used schema for Web API requests
@@ -647,7 +871,7 @@ func (c *Client) applyParams(params []interface{}) {
 // WARNING! As of 13 Jan 2019, 'https' schema is available only on non-free
 // tariff plans! If your tariff plan is 'free' and you'll change to the 'https'
 // you probably will get error when you will try to perform any request.
-func (r *tRequest) UseHTTPS(is bool) *tRequest {
+func (r *Request) UseHTTPS(is bool) *Request {
 	if r == nil {
 		return nil
 	}
@@ -660,14 +884,53 @@ func (r *tRequest) UseHTTPS(is bool) *tRequest {
 	return r
 }
 
-func (r *tRequest) EnableSecuity(is bool) *tRequest {
+// 'EnableSecurity' enables (if 'is' is true) or disables the security module.
+// If it's enabled, 'Security' field of 'Response' is filled by the info
+// about proxies, crawlers and threats of the requested IP.
+//
+// WARNING! You can use security module only if you have non-free
+// ipstack account.
+func (r *Request) EnableSecurity(is bool) *Request {
 	if r == nil {
 		return nil
 	}
-	if is == true {
-		r.securityEnabled = true
+	if is {
+		return r.setArg("security", "1")
 	}
+	return r.setArg("security", "")
+}
 
+// 'EnableSecuity' is the same as 'EnableSecurity'.
+//
+// Deprecated: Use 'EnableSecurity' instead.
+func (r *Request) EnableSecuity(is bool) *Request {
+	return r.EnableSecurity(is)
+}
+
+// 'Hostname' enables (if 'is' is true) or disables the hostname lookup.
+// If it's enabled, 'Hostname' field of 'Response' is filled by the hostname
+// the requested IP resolves to.
+func (r *Request) Hostname(is bool) *Request {
+	if r == nil {
+		return nil
+	}
+	if is {
+		return r.setArg("hostname", "1")
+	}
+	return r.setArg("hostname", "")
+}
+
+// 'WithContext' binds 'ctx' to the request object.
+// Each HTTP request performed by 'IP', 'IPs' or 'Me' methods will be
+// performed with that context, so you can cancel it or set a deadline.
+//
+// NOTE! It changes the current object. If you want to bind context only
+// for one query, get a fresh 'Request' object using 'R' method of 'Client'.
+func (r *Request) WithContext(ctx context.Context) *Request {
+	if r == nil {
+		return nil
+	}
+	r.ctx = ctx
 	return r
 }
 
@@ -677,98 +940,232 @@ func (r *tRequest) EnableSecuity(is bool) *tRequest {
 // You can pass as many fields as you want.
 // You can write field names manually or using predefined consts
 // started with 'Field...' prefix and described above.
-func (r *tRequest) Fields(fields ...string) *tRequest {
+//
+// WARNING! Unknown fields are rejected. The error (wraps 'ErrUnknownField')
+// is saved to the current object and will be returned by each request
+// ('IP', 'IPs', 'Me') w/o performing it.
+func (r *Request) Fields(fields ...Field) *Request {
 	if r == nil {
 		return nil
 	}
 	if len(fields) == 0 {
 		return r
 	}
-	// Save current value of fields only if it isn't empty
-	if rf := r.reqArgs.Get("fields"); rf != "" {
-		fields = append(fields, rf)
+	fs, err := NewFieldSet(fields...)
+	if err != nil && r.err == nil {
+		r.err = err
+	}
+	return r.WithFieldSet(fs)
+}
+
+// 'WithFieldSet' is the same as 'Fields' but takes the prepared 'FieldSet'
+// object. Fields of 'fs' are added to the already requested ones.
+func (r *Request) WithFieldSet(fs FieldSet) *Request {
+	if r == nil {
+		return nil
+	}
+	r.fields = r.fields.Union(fs)
+	return r.setArg("fields", r.fields.String())
+}
+
+// 'ResetFields' removes all requested fields, so all fields
+// will be returned by Web API (as if 'Fields' has never been called).
+func (r *Request) ResetFields() *Request {
+	if r == nil {
+		return nil
+	}
+	r.fields = FieldSet{}
+	return r.setArg("fields", "")
+}
+
+// 'WithoutFields' removes 'fields' from the requested fields
+// (see 'Difference' method of 'FieldSet'). If no fields have been requested
+// (all fields), all fields except 'fields' will be requested.
+//
+// WARNING! Unknown fields are rejected the same way as 'Fields' does.
+// And if all fields are removed, it means all fields again.
+func (r *Request) WithoutFields(fields ...Field) *Request {
+	if r == nil {
+		return nil
+	}
+	fs, err := NewFieldSet(fields...)
+	if err != nil && r.err == nil {
+		r.err = err
+	}
+	cur := r.fields
+	if cur.IsEmpty() {
+		cur = allFieldSet()
+	}
+	r.fields = cur.Difference(fs)
+	return r.setArg("fields", r.fields.String())
+}
+
+// 'setArg' sets the 'key' query parameter of Web API requests to 'value'
+// (or removes it, if 'value' is empty) and encodes all parameters again.
+//
+// Parameters are copied before changing, because they might be shared
+// with the base request of 'Client' or with the other copies (see 'copy').
+func (r *Request) setArg(key, value string) *Request {
+	args := make(url.Values, len(r.reqArgs)+1)
+	for k, v := range r.reqArgs {
+		args[k] = v
+	}
+	r.reqArgs = args
+	if value == "" {
+		r.reqArgs.Del(key)
+	} else {
+		r.reqArgs.Set(key, value)
 	}
-	// Combine all fields with comma separator, encode it and save
-	r.reqArgs.Set("fields", strings.Join(fields, ","))
 	r.reqArgsBuilt = "?" + r.reqArgs.Encode()
 	return r
 }
 
 // 'IP' is the one of endpoint to the ipstack Web API that provides
 // an info about some one IP address.
-// It checks the 'tRequest' object and 'ip' string validities and then
+// It checks the 'Request' object and 'ip' string validities and then
 // perform HTTP request to the Web API.
-// It returns the 'tResponse' object as it returned from 'do' method.
+// It returns the 'RawResponse' object as it returned from 'do' method.
 //
 // WARNING! This method have internal check validity of 'ip' address.
 // It means, if you pass not valid ip, the method willn't perform any
 // HTTP query and just return an error about it.
-func (r *tRequest) IP(ip string) *tResponse {
+// Valid 'ip' is canonicalized before sending (see 'canonicalIP' docs).
+func (r *Request) IP(ip string) *RawResponse {
+	rr := newRawResponse(OpIP, []string{ip})
 	// Validate 'this' object and arguments
 	if err := r.validate(); err != nil {
-		return resp(nil, err)
+		return rr.fail(err)
 	}
 	if ip = strings.TrimSpace(ip); ip == "" {
-		return resps(nil, "Empty IP")
+		return rr.fail(ErrEmptyIP)
 	}
-	if net.ParseIP(ip) == nil {
-		return resps(nil, "Invalid IP (%s)", ip)
+	canonical, ok := canonicalIP(ip)
+	if !ok {
+		return rr.fail(ErrInvalidIP)
 	}
 	// Make GET request, save result and error of request
-	return r.do(ip)
+	return r.do(rr, canonical)
 }
 
 // 'IPs' is the one of endpoint to the ipstack Web API that provides
 // an info about few IP addresses.
 // You can pass up to 50 IP addresses to the this method.
-// It checks the 'tRequest' object and each of 'ips' string validities and then
+// It checks the 'Request' object and each of 'ips' string validities and then
 // perform HTTP request to the Web API.
-// It returns the 'tResponse' object as it returned from 'do' method.
+// It returns the 'RawResponse' object as it returned from 'do' method.
 //
 // WARNING! This method have internal check validity of each passed 'ip'
 // address. It means, if you pass one or more not valid ip, the method
 // will ignore all not valid IP addresses and will perform HTTP query
 // only with valid.
-func (r *tRequest) IPs(ips ...string) *tResponse {
+//
+// NOTE! Valid IP addresses are canonicalized (see 'canonicalIP' docs) and
+// deduplicated, so each unique IP address is sent (and paid) only once.
+// But the response still contains one item per each valid passed IP address
+// in the same order: the response of each unique IP address is copied
+// to the all positions of its duplicates.
+// The successful response is always JSON array, even if only one
+// IP address has been passed.
+func (r *Request) IPs(ips ...string) *RawResponse {
+	rr := newRawResponse(OpIPs, ips)
 	// Validate 'this' object and arguments
 	if err := r.validate(); err != nil {
-		return resp(nil, err)
+		return rr.fail(err)
 	}
 	if len(ips) == 0 {
-		return resps(nil, "No IP passed")
+		return rr.fail(ErrNoIP)
 	}
-	// Declare slice that will contains only valid ips from 'ips' arg
-	// Validate each IP from 'ips' slice. Skip invalid IPs
-	validIps := make([]string, 0, len(ips))
+	// Validate and canonicalize each IP from 'ips' slice. Skip invalid IPs
+	canonicals := make([]string, 0, len(ips))
 	for _, ip := range ips {
-		if ip = strings.TrimSpace(ip); ip == "" {
-			continue
+		if canonical, ok := canonicalIP(ip); ok {
+			canonicals = append(canonicals, canonical)
 		}
-		if net.ParseIP(ip) == nil {
-			continue
+	}
+	return r.bulk(rr, canonicals)
+}
+
+// 'bulk' is the internal private auxiliary method and the common part
+// of all bulk requests.
+// It deduplicates already canonicalized IP addresses, performs request
+// with unique IP addresses and restores duplicates in the response
+// (see 'IPs' docs).
+func (r *Request) bulk(rr *RawResponse, canonicals []string) *RawResponse {
+	// Declare slice that will contains only unique ips from 'canonicals' arg
+	// and the slice of positions in 'uniqueIps' of each ip.
+	uniqueIps := make([]string, 0, len(canonicals))
+	positions := make([]int, 0, len(canonicals))
+	seen := make(map[string]int, len(canonicals))
+	for _, canonical := range canonicals {
+		pos, ok := seen[canonical]
+		if !ok {
+			pos = len(uniqueIps)
+			seen[canonical] = pos
+			uniqueIps = append(uniqueIps, canonical)
 		}
-		validIps = append(validIps, ip)
+		positions = append(positions, pos)
 	}
 	// Check how much valid ips has been passed as args
-	if len(validIps) == 0 {
-		return resps(nil, "No valid IP passed")
+	if len(uniqueIps) == 0 {
+		return rr.fail(ErrNoValidIP)
 	}
 	// Make GET request, save result and error of request
-	return r.do(strings.Join(validIps, ","))
+	// Restore duplicates in response if they were.
+	// If only one unique IP has been requested, Web API returns an object
+	// instead of array, and it's fixed by 'fanOut' too
+	rr = r.do(rr, strings.Join(uniqueIps, ","))
+	rr.fanOut(len(uniqueIps), positions)
+	return rr
+}
+
+// 'NetIP' is the same as 'IP' but takes golang 'net.IP' object.
+// It's checked and canonicalized w/o formatting to the string and parsing
+// it again.
+func (r *Request) NetIP(ip net.IP) *RawResponse {
+	rr := newRawResponse(OpIP, []string{ip.String()})
+	if err := r.validate(); err != nil {
+		return rr.fail(err)
+	}
+	canonical, ok := canonicalNetIP(ip)
+	if !ok {
+		return rr.fail(ErrInvalidIP)
+	}
+	return r.do(rr, canonical)
+}
+
+// 'NetIPs' is the same as 'IPs' but takes golang 'net.IP' objects.
+// They are checked and canonicalized w/o formatting to the strings
+// and parsing them again.
+func (r *Request) NetIPs(ips ...net.IP) *RawResponse {
+	rr := newRawResponse(OpIPs, netIPStrings(ips))
+	if err := r.validate(); err != nil {
+		return rr.fail(err)
+	}
+	if len(ips) == 0 {
+		return rr.fail(ErrNoIP)
+	}
+	canonicals := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		if canonical, ok := canonicalNetIP(ip); ok {
+			canonicals = append(canonicals, canonical)
+		}
+	}
+	return r.bulk(rr, canonicals)
 }
 
 // 'Me' is the one of endpoint to the ipstack Web API that provides
 // an info about the IP address you're owner of.
-// It checks the 'tRequest' object validity and then perform HTTP request
+// It checks the 'Request' object validity and then perform HTTP request
 // to the Web API.
-// It returns the 'tResponse' object as it returned from 'do' method.
-func (r *tRequest) Me() *tResponse {
+// It returns the 'RawResponse' object as it returned from 'do' method.
+func (r *Request) Me() *RawResponse {
+	rr := newRawResponse(OpMe, nil)
 	// Validate 'this' object and arguments
 	if err := r.validate(); err != nil {
-		return resp(nil, err)
+		return rr.fail(err)
 	}
 	// Make GET request, return raw response with request error and raw response
-	return r.do("check")
+	return r.do(rr, "check")
 }
 
 // 'validate' is auxiliary method for all public 'Client' methods.
@@ -780,17 +1177,22 @@ func (r *tRequest) Me() *tResponse {
 // method error probably will be returned by the caller too.
 //
 // Errors and its reasons:
-// "Nil client object": You tries to call some method of the nil object
-// of client. For example: (*Client)(nil).<some_method>(...).
-// "Incorrect internal state": Probably you create 'Client' object directly
+// 'ErrNilRequest': You tries to call some method of the nil object
+// of request. For example: (*Request)(nil).<some_method>(...).
+// 'ErrNilHTTPClient': Probably you create 'Request' object directly
 // and now you tries to call some method of that object. It's not allowed.
-// Use 'New' constructor instead to create a 'Client' instance.
-func (r *tRequest) validate() error {
+// Use 'R' method of 'Client' instead to get a 'Request' instance.
+// Any other error: the request has been configured incorrectly
+// (unknown fields has been passed to 'Fields' method, for example).
+func (r *Request) validate() error {
 	if r == nil {
-		return fmt.Errorf("Nil request object")
+		return ErrNilRequest
 	}
 	if r.client == nil {
-		return fmt.Errorf("Nil http.Client object in request")
+		return ErrNilHTTPClient
+	}
+	if r.err != nil {
+		return r.err
 	}
 	return nil
 }
@@ -800,70 +1202,201 @@ func (r *tRequest) validate() error {
 // This method generates the full URL string for GET request, then
 // perform GET request using generated URL.
 // If request was successfull, 'do' tries to read a whole response,
-// and save it as 'RawData' field in the returned object.
+// and save it as 'RawData' field in the 'rr' object, and returns it.
 // If any error occurred, it guarantees that 'RawData' field is empty (nil)
-// and 'Error' field contains error object.
+// and 'Error' field contains error object ('OpError' that wraps the cause).
 //
 // So, 'do' work can be split to the subtasks:
 // 1. Generate URL
 // 2. Perform HTTP/S GET request
 // 3. Read a whole JSON response
 // *. Check error on each of steps above
-func (r *tRequest) do(method string) *tResponse {
+func (r *Request) do(rr *RawResponse, method string) *RawResponse {
 	// Make GET request, if any error occur, return it
 	url := r.endpoint + method + r.reqArgsBuilt
-	if r.securityEnabled {
-		url = url + "&" + securityParam
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return rr.fail(err)
 	}
-	rr, err := r.client.Get(url)
+	if r.ctx != nil {
+		req = req.WithContext(r.ctx)
+	}
+	// Requests aren't retried, so it's always the first attempt
+	rr.attempt = 1
+	hr, err := r.client.Do(req)
 	if err != nil {
-		return resp(nil, err)
+		return rr.fail(err)
 	}
+	rr.status = hr.StatusCode
+	rr.lang = r.lang
+	rr.format = r.format
+	rr.codec = r.codec
 	// AFAIK it's not possible, but anyway, if 'Body' of response is nil,
 	// return error
-	if rr.Body == nil {
-		return resps(nil, "Body of GET response is nil")
+	if hr.Body == nil {
+		return rr.fail(ErrNilBody)
 	}
 	// Try to read all as []byte from 'Body' response, and close io.Reader
 	// right after reading (w/o deferring because it isn't necessary here)
-	b, err := ioutil.ReadAll(rr.Body)
-	_ = rr.Body.Close()
+	b, err := ioutil.ReadAll(hr.Body)
+	_ = hr.Body.Close()
 	// Check error of reading. If it's not nil, return it
 	// Otherwise return readed data
-	if err == nil {
-		return resp(b, nil)
+	if err != nil {
+		return rr.fail(err)
 	}
-	return resps(nil, "Error reading Body of GET response (%s)", err)
+	rr.RawData = b
+	return rr
 }
 
-// 'resp' returns the 'tResponse' object created from 'rawData' and 'err'
-// objects.
-// This function just allocate and create an 'tResponse' instance
-// and saves 'rawData' and 'err' objects to the created object.
-func resp(rawData []byte, err error) *tResponse {
-	return &tResponse{RawData: rawData, Error: err}
+// 'newRawResponse' returns the empty 'RawResponse' object of the operation
+// 'op' (one of 'Op...' consts) with queried IP addresses 'ips'.
+// The operation and IP addresses are used to create 'OpError' object
+// if any error will occur.
+func newRawResponse(op string, ips []string) *RawResponse {
+	return &RawResponse{op: op, ips: ips}
 }
 
-// 'resps' returns the 'tResponse' object created from 'rawData' and
-// 'serr', 'args' objects.
-// If 'serr' isn't empty it will be treated as format string to generate
-// an error object and 'args' as args for printf-like format string.
-// This function just allocate and create an 'tResponse' instance
-// and saves 'rawData' and generated error object to the created object.
-func resps(rawData []byte, serr string, args ...interface{}) *tResponse {
-	r := &tResponse{RawData: rawData}
-	if serr != "" {
-		r.Error = fmt.Errorf(serr, args...)
+// 'wrap' returns the 'OpError' object that wraps 'err' and contains
+// the operation, queried IP addresses, HTTP status code and the number
+// of attempt of the current object.
+func (r *RawResponse) wrap(err error) *OpError {
+	return &OpError{
+		Op:         r.op,
+		IPs:        r.ips,
+		StatusCode: r.status,
+		Attempt:    r.attempt,
+		Err:        err,
 	}
+}
+
+// 'fail' saves wrapped 'err' (see 'wrap') as 'Error' field of the current
+// object, drops 'RawData' and returns the current object.
+func (r *RawResponse) fail(err error) *RawResponse {
+	r.RawData, r.Error = nil, r.wrap(err)
 	return r
 }
 
-// 'copy' is the internal auxiliary method of 'tRequest' object.
-// This method creates the full copy of 'tRequest' object and return it.
-// It needs to guarantee that applying some changes to the 'tRequest'
+// 'canonicalIP' returns the canonical text form of 'ip' and true,
+// or an empty string and false if 'ip' isn't valid IP address.
+//
+// Canonicalization means:
+// - Spaces around are trimmed;
+// - Leading zeros of IPv4 octets are removed (decimal, not octal);
+// - IPv4-mapped IPv6 addresses become IPv4 ("::ffff:1.2.3.4" -> "1.2.3.4");
+// - IPv6 addresses are compressed and lower cased ("2001:DB8:0::01" -> "2001:db8::1").
+func canonicalIP(ip string) (string, bool) {
+	if ip = strings.TrimSpace(ip); ip == "" {
+		return "", false
+	}
+	// Leading zeros are allowed only in the dotted IPv4 part,
+	// that might be the tail of IPv6 address
+	if dot := strings.IndexByte(ip, '.'); dot >= 0 {
+		tail := strings.LastIndexByte(ip, ':') + 1
+		octets := strings.Split(ip[tail:], ".")
+		for i, octet := range octets {
+			if octets[i] = strings.TrimLeft(octet, "0"); octets[i] == "" && octet != "" {
+				octets[i] = "0"
+			}
+		}
+		ip = ip[:tail] + strings.Join(octets, ".")
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return "", false
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return v4.String(), true
+	}
+	return parsed.String(), true
+}
+
+// 'canonicalNetIP' is the same as 'canonicalIP' but for golang 'net.IP'
+// object: IPv4-mapped IPv6 addresses become IPv4, and IPv6 addresses
+// are compressed. 'ip' is valid only if it's 4 or 16 bytes length.
+func canonicalNetIP(ip net.IP) (string, bool) {
+	if v4 := ip.To4(); v4 != nil {
+		return v4.String(), true
+	}
+	if len(ip) != net.IPv6len {
+		return "", false
+	}
+	return ip.String(), true
+}
+
+// 'netIPStrings' returns the text forms of 'ips'.
+// It used to fill 'IPs' field of 'OpError' object.
+func netIPStrings(ips []net.IP) []string {
+	s := make([]string, len(ips))
+	for i := range ips {
+		s[i] = ips[i].String()
+	}
+	return s
+}
+
+// 'fanOut' is the internal auxiliary method of 'RawResponse' object.
+// It restores the response of bulk request with 'unique' deduplicated
+// IP addresses to the response with all passed IP addresses: 'positions' is
+// the index in the response of each passed IP address.
+//
+// If response has an error or isn't the successful JSON response
+// (array or single object if only one unique IP has been requested),
+// it's left as is. But if the number of items in the response differs
+// from 'unique', response fails with 'ErrResponseMismatch'.
+func (r *RawResponse) fanOut(unique int, positions []int) {
+	if r == nil || r.Error != nil {
+		return
+	}
+	if r.isXML() {
+		r.fanOutXML(unique, positions)
+		return
+	}
+	data := bytes.TrimSpace(r.RawData)
+	items := []json.RawMessage{}
+	switch {
+	case len(data) > 0 && data[0] == '[':
+		if r.getCodec().Unmarshal(data, &items) != nil {
+			return
+		}
+		if len(items) == unique && unique == len(positions) {
+			return // Nothing to restore
+		}
+	case len(data) > 0 && data[0] == '{':
+		errApi := tResponseError{Success: true}
+		if r.getCodec().Unmarshal(data, &errApi) != nil || !errApi.Success {
+			return
+		}
+		items = append(items, data)
+	default:
+		return
+	}
+	if len(items) != unique {
+		r.fail(fmt.Errorf("%w (%d, expected %d)", ErrResponseMismatch, len(items), unique))
+		return
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, len(positions)*len(data)/len(items)+2))
+	buf.WriteByte('[')
+	for i, pos := range positions {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(items[pos])
+	}
+	buf.WriteByte(']')
+	r.RawData = buf.Bytes()
+}
+
+// 'copy' is the internal auxiliary method of 'Request' object.
+// This method creates the full copy of 'Request' object and return it.
+// It needs to guarantee that applying some changes to the 'Request'
 // object that will be got by user using 'R' method do not affected
-// default client 'tRequest' object.
-func (r *tRequest) copy() *tRequest {
+// default client 'Request' object.
+//
+// It's the shallow copy: request args ('reqArgs') are shared until
+// one of the objects changes them, and then they are copied by 'setArg'
+// (copy-on-write), field set is a value. The golang HTTP client, context
+// and codec are shared, because they aren't changed by 'Request' methods.
+func (r *Request) copy() *Request {
 	if r == nil {
 		return nil
 	}
@@ -871,55 +1404,107 @@ func (r *tRequest) copy() *tRequest {
 	return &rr
 }
 
+// 'Clone' returns the independent copy of the current object.
+// Any changes of the copy ('Fields', 'ResetFields', 'WithoutFields',
+// 'Language', etc) do not affect the current object and vice versa.
+// It's useful to prepare a few requests from one configured request.
+//
+// NOTE! 'R' method of 'Client' already returns such copy
+// of the client's request, so you do not need to clone it.
+func (r *Request) Clone() *Request {
+	return r.copy()
+}
+
 // 'CheckError' checks if Web API response has an error.
 // So, if any error has occur when Web API request was performing,
 // this method return an occurred error immediately.
-// If returned JSON response contains API error, the 'tError' instance
+// If returned JSON (or XML) response contains API error, the 'APIErr' instance
 // object will be created and will be stored as 'Error' field in the current
-// 'tResponse' object and also returned as object of 'error' interface.
-func (r *tResponse) CheckError() error {
+// 'RawResponse' object and also returned as object of 'error' interface.
+func (r *RawResponse) CheckError() error {
 	if r == nil {
-		return fmt.Errorf("Nil RAW response object")
+		return &OpError{Err: ErrNilResponse}
 	}
 	// Check if error already occur (request error)
 	if r.Error != nil {
 		return r.Error
 	}
+	if r.isXML() {
+		errApi, err := checkXMLError(r.RawData)
+		if err != nil {
+			r.Error = r.wrap(err)
+		} else if errApi != nil {
+			r.Error = r.wrap(errApi)
+		}
+		return r.Error
+	}
+	// Web API error is always an object, so response of bulk request
+	// (JSON array) can't be an error
+	if trimmed := bytes.TrimSpace(r.RawData); len(trimmed) > 0 && trimmed[0] == '[' {
+		return nil
+	}
 	// Try to decode response JSON to the error object, in which already
 	// 'success' field is set to the 'true'
 	// If error really occurred, it will be overwritten to the 'false'.
 	// It's the fastest way to check error from API I can imagine now
 	errApi := tResponseError{Success: true}
-	r.Error = json.Unmarshal(r.RawData, &errApi)
-	if r.Error != nil {
-		return fmt.Errorf("Decode JSON error (%s)", r.Error)
+	if err := r.getCodec().Unmarshal(r.RawData, &errApi); err != nil {
+		r.Error = r.wrap(err)
+		return r.Error
 	}
 	if errApi.Success == false {
-		r.Error = &errApi.Error
+		r.Error = r.wrap(&errApi.Error)
 		return r.Error
 	}
 	return nil
 }
 
+// 'decode' is the internal private auxiliary method of 'RawResponse' class.
+// It checks whether API return an error as encoded JSON in the current
+// object and then tries to decode encoded JSON to 'dst'.
+//
+// Only the small envelope of Web API error ('tResponseError') is decoded
+// by 'CheckError', so the payload is decoded in full only by 'DecodeTo'.
+func (r *RawResponse) decode(dst interface{}) error {
+	if err := r.CheckError(); err != nil {
+		return err
+	}
+	return r.DecodeTo(dst)
+}
+
 // 'DecodeTo' tries to unmarshal Web API JSON response stored in the current
-// 'tResponse' object as 'RawData' field to the 'i'.
+// 'RawResponse' object as 'RawData' field to the 'i'.
 // If any error occurred while trying to decode JSON or already occurred
 // ('Error' field isn't empty), the occurred error (from 'Error' filed)
 // will be returned.
-func (r *tResponse) DecodeTo(i interface{}) error {
+//
+// XML response (see 'Format' method of 'Request') is decoded by XML decoder.
+// If 'i' is the pointer to slice, each child element of the root element
+// is decoded as item of that slice.
+func (r *RawResponse) DecodeTo(i interface{}) error {
 	if r == nil {
-		return fmt.Errorf("Nil RAW response object")
+		return &OpError{Err: ErrNilResponse}
 	}
 	if r.Error != nil {
 		return r.Error
 	}
 	if i == nil {
-		return fmt.Errorf("Nil destination argument")
+		return r.wrap(ErrNilDestination)
 	}
-	return json.Unmarshal(r.RawData, i)
+	var err error
+	if r.isXML() {
+		err = decodeXML(r.RawData, i)
+	} else {
+		err = unmarshalWith(r.getCodec(), r.RawData, i)
+	}
+	if err != nil {
+		return r.wrap(err)
+	}
+	setLang(i, r.lang)
+	return nil
 }
 
-// 'Code' is the auxiliary method of 'tError' class.
+// 'Code' is the auxiliary method of 'APIErr' class.
 // It exists to provide the more convenient way to check error code
 // of some error object.
 //
@@ -929,17 +1514,17 @@ func (r *tResponse) DecodeTo(i interface{}) error {
 // In that case, you can just write:
 // 'APIError(err).Code()' if that call will return 0 it means that:
 // - No error occur and 'err' is nil object of 'error' interface
-// - Some error occur, but 'err' isn't tError' object (err != nil)
+// - Some error occur, but 'err' isn't APIErr' object (err != nil)
 //
 // Check 'APIError' docs for details.
-func (e *tError) Code() int {
+func (e *APIErr) Code() int {
 	if e == nil {
 		return 0
 	}
 	return e.RawCode
 }
 
-// 'Type' is the auxiliary method of 'tError' class.
+// 'Type' is the auxiliary method of 'APIErr' class.
 // It exists to provide the more convenient way to check error type
 // of some error object.
 //
@@ -949,17 +1534,17 @@ func (e *tError) Code() int {
 // In that case, you can just write:
 // 'APIError(err).Typw()' if that call will return empty string it means that:
 // - No error occur and 'err' is nil object of 'error' interface
-// - Some error occur, but 'err' isn't tError' object (err != nil)
+// - Some error occur, but 'err' isn't APIErr' object (err != nil)
 //
 // Check 'APIError' docs for details.
-func (e *tError) Type() string {
+func (e *APIErr) Type() string {
 	if e == nil {
 		return ""
 	}
 	return e.RawType
 }
 
-// 'Info' is the auxiliary method of 'tError' class.
+// 'Info' is the auxiliary method of 'APIErr' class.
 // It exists to provide the more convenient way to check error info
 // of some error object.
 //
@@ -969,23 +1554,23 @@ func (e *tError) Type() string {
 // In that case, you can just write:
 // 'APIError(err).Info()' if that call will return 0 it means that:
 // - No error occur and 'err' is nil object of 'error' interface
-// - Some error occur, but 'err' isn't tError' object (err != nil)
+// - Some error occur, but 'err' isn't APIErr' object (err != nil)
 //
 // Check 'APIError' docs for details.
-func (e *tError) Info() string {
+func (e *APIErr) Info() string {
 	if e == nil {
 		return ""
 	}
 	return e.RawInfo
 }
 
-// 'Error' implements the 'error' interface for 'tError' class.
+// 'Error' implements the 'error' interface for 'APIErr' class.
 // It returns the string that represents a whole Web API error.
 // Returned string will be created by the following pattern:
 // "[<error_code>]: <error_type> (<error_info>)".
 //
-// Seee 'tError' docs for more details.
-func (e *tError) Error() string {
+// Seee 'APIErr' docs for more details.
+func (e *APIErr) Error() string {
 	if e == nil {
 		return ""
 	}
@@ -1024,13 +1609,28 @@ func (e *tError) Error() string {
 // If this argument willn't pass, the HTTP client with default params
 // will be used (see docs for 'http.Client' golang package).
 func New(params ...interface{}) (*Client, error) {
-	c := &Client{baseReq: &tRequest{reqArgs: url.Values{}}}
+	c := &Client{
+		baseReq:      &Request{reqArgs: url.Values{}},
+		asyncWorkers: cAsyncDefaultWorkers,
+		asyncQueue:   cAsyncDefaultQueue,
+	}
 	// Apply all params
 	c.applyParams(params)
+	// Checked values aren't needed after applying params
+	paramErrs := c.paramErrs
+	c.paramErrs, c.paramsSeen = nil, nil
+	// In strict mode each misconfiguration is reported at once
+	if c.strict && len(paramErrs) > 0 {
+		return nil, &OpError{Op: OpNew, Err: &ParamsError{Errs: paramErrs}}
+	}
+	// Some params might be invalid (unknown fields, for example)
+	if c.baseReq.err != nil {
+		return nil, &OpError{Op: OpNew, Err: c.baseReq.err}
+	}
 	// Try to extract token from params, if it's not set already, save it
 	if c.baseReq.token == "" {
 		if c.baseReq.token = extractToken(params); c.baseReq.token == "" {
-			return nil, fmt.Errorf("Token argument (string or []byte) is required")
+			return nil, &OpError{Op: OpNew, Err: ErrNoToken}
 		}
 	}
 	// Try to extract golang http.Client object from params,
@@ -1046,20 +1646,21 @@ func New(params ...interface{}) (*Client, error) {
 		c.baseReq.endpoint = cApiEndpointHTTP
 	}
 	c.baseReq.reqArgs.Set("access_key", c.baseReq.token)
-	c.baseReq.reqArgs.Set("output", "json")
+	if c.baseReq.format == "" {
+		c.baseReq.format = FormatJSON
+	}
+	c.baseReq.reqArgs.Set("output", string(c.baseReq.format))
 	c.baseReq.reqArgsBuilt = "?" + c.baseReq.reqArgs.Encode()
 	// Try to perform first query if it's need
 	if !c.skipInitFetchMe {
 		if _, err := c.Me(); err != nil {
-			return nil, fmt.Errorf("Test request error (%s)", err)
+			return nil, &OpError{Op: OpNew, Err: err}
 		}
 	}
 	// All good, return 'Client' object and nil as error
 	// And also save it as default client if this operation hasn't been
 	// performed earlier.
-	if DefaultClient == nil {
-		DefaultClient = c
-	}
+	setDefaultIfNil(c)
 	return c, nil
 }
 
@@ -1113,14 +1714,21 @@ func extractHttpClient(params []interface{}) (client *http.Client) {
 
 // 'ParamToken' creates a parameter for 'Client' constructors that
 // specifies the API token, 'Client' object must be created with.
+//
+// NOTE! Empty token is ignored, but it's reported as invalid value
+// in strict mode (see 'ParamStrict').
 func ParamToken(token string) tClientParam {
-	if token = strings.TrimSpace(token); token == "" {
-		return nil
-	}
+	token = strings.TrimSpace(token)
 	return func(c *Client) {
-		if c != nil {
-			c.baseReq.token = token
+		if c == nil {
+			return
 		}
+		if token == "" {
+			c.paramError(fmt.Errorf("%w: empty token", ErrInvalidParam))
+			return
+		}
+		c.noteParam("token", secretDigest(token))
+		c.baseReq.token = token
 	}
 }
 
@@ -1133,10 +1741,15 @@ func ParamToken(token string) tClientParam {
 // treates as succeessfully created (and response object is decoded
 // and stored as info of the current IP address and will be available
 // by calling 'Me' method w/o force fetch).
-func ParamDisableFirstMeCall() tClientParam {
+//
+// You can pass false to enable the first query back
+// (ParamDisableFirstMeCall() is the same as ParamDisableFirstMeCall(true)).
+func ParamDisableFirstMeCall(is ...bool) tClientParam {
+	disable := len(is) == 0 || is[0]
 	return func(c *Client) {
 		if c != nil {
-			c.skipInitFetchMe = true
+			c.noteParam("first Me call", strconv.FormatBool(!disable))
+			c.skipInitFetchMe = disable
 		}
 	}
 }
@@ -1148,6 +1761,7 @@ func ParamDisableFirstMeCall() tClientParam {
 func ParamUseHTTPS(is bool) tClientParam {
 	return func(c *Client) {
 		if c != nil {
+			c.noteParam("https", strconv.FormatBool(is))
 			c.baseReq = c.baseReq.UseHTTPS(is)
 		}
 	}
@@ -1159,7 +1773,10 @@ func ParamUseHTTPS(is bool) tClientParam {
 // You can pass as many fields as you want.
 // You can write field names manually or using predefined consts
 // started with 'Field...' prefix and described above.
-func ParamFields(fields ...string) tClientParam {
+//
+// WARNING! Unknown fields are rejected and 'Client' constructor returns
+// an error (wraps 'ErrUnknownField').
+func ParamFields(fields ...Field) tClientParam {
 	return func(c *Client) {
 		if c != nil {
 			c.baseReq = c.baseReq.Fields(fields...)
@@ -1167,14 +1784,61 @@ func ParamFields(fields ...string) tClientParam {
 	}
 }
 
+// 'ParamFieldSet' is the same as 'ParamFields' but takes the prepared
+// 'FieldSet' object.
+func ParamFieldSet(fs FieldSet) tClientParam {
+	return func(c *Client) {
+		if c != nil {
+			c.baseReq = c.baseReq.WithFieldSet(fs)
+		}
+	}
+}
+
+// 'ParamWithoutFields' creates a parameter for 'Client' constructors that
+// removes 'fields' from the fields requested by previous parameters
+// (see 'WithoutFields' method of 'Request').
+//
+// WARNING! Unknown fields are rejected and 'Client' constructor returns
+// an error (wraps 'ErrUnknownField').
+func ParamWithoutFields(fields ...Field) tClientParam {
+	return func(c *Client) {
+		if c != nil {
+			c.baseReq = c.baseReq.WithoutFields(fields...)
+		}
+	}
+}
+
+// 'ParamResetFields' creates a parameter for 'Client' constructors that
+// removes all fields requested by previous parameters, so all fields
+// will be returned by Web API (see 'ResetFields' method of 'Request').
+func ParamResetFields() tClientParam {
+	return func(c *Client) {
+		if c != nil {
+			c.baseReq = c.baseReq.ResetFields()
+		}
+	}
+}
+
 // 'ParamEnableSecurity' creates a parameter for 'Client' constructors that
-// enables the security module
+// enables (or disables if 'is' is false) the security module.
 //
 // WARNING! You can use securuty module only if you have non-free ipstack account.
 func ParamEnableSecurity(is bool) tClientParam {
 	return func(c *Client) {
 		if c != nil {
-			c.baseReq = c.baseReq.EnableSecuity(is)
+			c.noteParam("security", strconv.FormatBool(is))
+			c.baseReq = c.baseReq.EnableSecurity(is)
+		}
+	}
+}
+
+// 'ParamHostname' creates a parameter for 'Client' constructors that
+// enables the hostname lookup ('Hostname' field of 'Response').
+func ParamHostname(is bool) tClientParam {
+	return func(c *Client) {
+		if c != nil {
+			c.noteParam("hostname", strconv.FormatBool(is))
+			c.baseReq = c.baseReq.Hostname(is)
 		}
 	}
 }
@@ -1208,65 +1872,62 @@ func Init(params ...interface{}) error {
 	if err != nil {
 		return err
 	}
-	DefaultClient = cl
+	SetDefault(cl)
 	return nil
 }
 
 // 'R' is the same as 'R' of any 'Client' instance but only for default client.
 // See docs for 'Client.R' method and 'DefaultClient' variable for details.
-func R() *tRequest {
-	return DefaultClient.R()
+func R() *Request {
+	return Default().R()
 }
 
 // 'IP' is the same as 'IP' of any 'Client' instance
 // but only for default client.
 // See docs for 'Client.IP' method and 'DefaultClient' variable for details.
 func IP(ip string) (*Response, error) {
-	if DefaultClient != nil {
-		return DefaultClient.IP(ip)
+	if c := Default(); c != nil {
+		return c.IP(ip)
 	}
-	return nil, fmt.Errorf("DefaultClient client isn't initialized")
+	return nil, &OpError{Op: OpIP, IPs: []string{ip}, Err: ErrNoDefaultClient}
 }
 
 // 'IPs' is the same as 'IPs' of any 'Client' instance
 // but only for default client.
 // See docs for 'Client.IPs' method and 'DefaultClient' variable for details.
 func IPs(ips ...string) ([]*Response, error) {
-	if DefaultClient != nil {
-		return DefaultClient.IPs(ips...)
+	if c := Default(); c != nil {
+		return c.IPs(ips...)
 	}
-	return nil, fmt.Errorf("DefaultClient client isn't initialized")
+	return nil, &OpError{Op: OpIPs, IPs: ips, Err: ErrNoDefaultClient}
 }
 
 // 'Me' is the same as 'Me' of any 'Client' instance
 // but only for default client.
 // See docs for 'Client.Me' method and 'DefaultClient' variable for details.
 func Me(forceFetch ...bool) (*Response, error) {
-	if DefaultClient != nil {
-		return DefaultClient.Me(forceFetch...)
+	if c := Default(); c != nil {
+		return c.Me(forceFetch...)
 	}
-	return nil, fmt.Errorf("DefaultClient client isn't initialized")
+	return nil, &OpError{Op: OpMe, Err: ErrNoDefaultClient}
 }
 
-// 'APIError' tries to cast 'e' object to the 'tError' object.
-// 'tError' is the internal private class, represents the some Web API error.
-// If 'e' is the object of 'tError' class, it will be returned by pointer,
+// 'APIError' tries to cast 'e' object to the 'APIErr' object.
+// 'APIErr' is the class that represents the some Web API error.
+// If 'e' is the object of 'APIErr' class, it will be returned by pointer,
 // otherwise nil is returned.
 //
 // Because all methods that returns an error object, return object of 'error'
-// interface, not an 'tError' instance, in golang you must check if
-// some object that implements 'error' interface is really 'tError' instance.
+// interface, not an 'APIErr' instance, in golang you must check if
+// some object that implements 'error' interface is really 'APIErr' instance.
 // You can use this function for it and then, for example, if you
 // want to check the error code, just call 'Code' method.
-// Even if this method will return nil as '*tError' object, method 'Code'
+// Even if this method will return nil as '*APIErr' object, method 'Code'
 // willn't panic and 0 will be returned by 'Code'.
 // Check methods 'Code', 'Type' and 'Info' for details.
-func APIError(e error) *tError {
-	if e == nil {
-		return nil
-	}
-	if op, ok := e.(*tError); ok {
-		return op
-	}
-	return nil
+//
+// NOTE! If 'e' wraps another errors (has 'Unwrap' method), the whole chain
+// is checked and the first 'APIErr' object is returned.
+func APIError(e error) *APIErr {
+	return findAPIErr(e)
 }
@@ -0,0 +1,375 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// 'tFakeTransport' is the golang HTTP transport that doesn't perform
+// real requests, but answers by the status code and body returned by itself.
+type tFakeTransport func(req *http.Request) (int, string)
+
+// 'RoundTrip' implements the 'http.RoundTripper' interface
+// for 'tFakeTransport' class.
+func (f tFakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	status, body := f(req)
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+// 'newFakeClient' creates a new 'Client' object that performs requests
+// using 'transport' and doesn't perform the first 'Me' query.
+func newFakeClient(t testing.TB, transport http.RoundTripper, params ...interface{}) *Client {
+	t.Helper()
+	params = append([]interface{}{
+		"test-token",
+		ParamDisableFirstMeCall(),
+		&http.Client{Transport: transport},
+	}, params...)
+	c, err := New(params...)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return c
+}
+
+// 'queriedIPs' returns IP addresses queried by 'req'
+// ("check" for the 'Me' request).
+func queriedIPs(req *http.Request) []string {
+	return strings.Split(strings.TrimPrefix(req.URL.Path, "/"), ",")
+}
+
+// 'echoJSON' answers like Web API, but only IP address and the fake
+// country code are returned for each queried IP address.
+// The 'Me' request is answered by "9.9.9.9".
+func echoJSON(req *http.Request) (int, string) {
+	ips := queriedIPs(req)
+	items := make([]string, len(ips))
+	for i, ip := range ips {
+		if ip == "check" {
+			ip = "9.9.9.9"
+		}
+		items[i] = fmt.Sprintf(`{"ip":%q,"country_code":"US"}`, ip)
+	}
+	if len(items) == 1 {
+		return http.StatusOK, items[0]
+	}
+	return http.StatusOK, "[" + strings.Join(items, ",") + "]"
+}
+
+func TestIPsFanOut(t *testing.T) {
+	cases := []struct {
+		name   string
+		format Format
+		ips    []string
+		body   string
+		want   []string
+		err    error
+	}{
+		{"duplicates", FormatJSON, []string{"1.1.1.1", "2.2.2.2", "1.1.1.1", "01.1.1.1"},
+			`[{"ip":"1.1.1.1"},{"ip":"2.2.2.2"}]`, []string{"1.1.1.1", "2.2.2.2", "1.1.1.1", "1.1.1.1"}, nil},
+		{"no duplicates", FormatJSON, []string{"1.1.1.1", "2.2.2.2"},
+			`[{"ip":"1.1.1.1"},{"ip":"2.2.2.2"}]`, []string{"1.1.1.1", "2.2.2.2"}, nil},
+		{"single object", FormatJSON, []string{"1.1.1.1"},
+			`{"ip":"1.1.1.1"}`, []string{"1.1.1.1"}, nil},
+		{"single object with duplicates", FormatJSON, []string{"1.1.1.1", "bogus", "1.1.1.1"},
+			`{"ip":"1.1.1.1"}`, []string{"1.1.1.1", "1.1.1.1"}, nil},
+		{"empty array", FormatJSON, []string{"1.1.1.1", "1.1.1.1"},
+			`[]`, nil, ErrResponseMismatch},
+		{"too few items", FormatJSON, []string{"1.1.1.1", "2.2.2.2", "3.3.3.3"},
+			`[{"ip":"1.1.1.1"},{"ip":"2.2.2.2"}]`, nil, ErrResponseMismatch},
+		{"api error", FormatJSON, []string{"1.1.1.1", "1.1.1.1"},
+			usageLimitJSON, nil, ErrUsageLimitReached},
+		{"xml duplicates", FormatXML, []string{"1.1.1.1", "2.2.2.2", "1.1.1.1"},
+			`<results><result><ip>1.1.1.1</ip></result><result><ip>2.2.2.2</ip></result></results>`,
+			[]string{"1.1.1.1", "2.2.2.2", "1.1.1.1"}, nil},
+		{"xml single", FormatXML, []string{"1.1.1.1", "1.1.1.1"},
+			`<result><ip>1.1.1.1</ip><country_code>US</country_code></result>`,
+			[]string{"1.1.1.1", "1.1.1.1"}, nil},
+		{"xml empty", FormatXML, []string{"1.1.1.1", "1.1.1.1"},
+			`<results></results>`, nil, ErrResponseMismatch},
+	}
+	for _, tc := range cases {
+		body := tc.body
+		c := newFakeClient(t, tFakeTransport(func(*http.Request) (int, string) {
+			return http.StatusOK, body
+		}), ParamFormat(tc.format))
+		rs, err := c.IPs(tc.ips...)
+		if tc.err != nil {
+			if !errors.Is(err, tc.err) {
+				t.Errorf("%s: got error %v, expected %v", tc.name, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		got := make([]string, len(rs))
+		for i := range rs {
+			got[i] = rs[i].IP
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %v, expected %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+// 'keepDefault' restores the default client after the test.
+func keepDefault(t *testing.T) {
+	prev := Default()
+	t.Cleanup(func() { SetDefault(prev) })
+}
+
+func TestClientMeIsCached(t *testing.T) {
+	calls := 0
+	c := newFakeClient(t, tFakeTransport(func(req *http.Request) (int, string) {
+		calls++
+		return echoJSON(req)
+	}))
+	first, err := c.Me()
+	if err != nil {
+		t.Fatalf("Me: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		me, err := c.Me()
+		if err != nil {
+			t.Fatalf("Me: %v", err)
+		}
+		if me != first || me.IP != "9.9.9.9" {
+			t.Fatalf("Me: got %p (IP %q), expected cached %p", me, me.IP, first)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("Me: %d requests performed, expected 1", calls)
+	}
+	// Fresh info is decoded to the new object
+	fresh, err := c.Me(true)
+	if err != nil || calls != 2 {
+		t.Fatalf("Me(true): err %v, %d requests performed, expected 2", err, calls)
+	}
+	if fresh == first || first.IP != "9.9.9.9" {
+		t.Fatal("Me(true): cached object is changed")
+	}
+	if me, _ := c.Me(); me != fresh {
+		t.Fatal("Me: cache isn't updated by Me(true)")
+	}
+}
+
+// Run with -race flag: lookups of one 'Client' from many goroutines.
+func TestConcurrentLookups(t *testing.T) {
+	c := newFakeClient(t, tFakeTransport(echoJSON))
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				ip := fmt.Sprintf("10.0.%d.%d", g, i)
+				if r, err := c.IP(ip); err != nil || r.IP != ip {
+					t.Errorf("IP(%s): %v, %+v", ip, err, r)
+				}
+				if rs, err := c.IPs(ip, "1.1.1.1", ip); err != nil || len(rs) != 3 || rs[2].IP != ip {
+					t.Errorf("IPs(%s): %v, %d items", ip, err, len(rs))
+				}
+				if me, err := c.Me(i%5 == 0); err != nil || me.IP != "9.9.9.9" {
+					t.Errorf("Me: %v, %+v", err, me)
+				}
+				if err := c.R().Fields(FieldCity).IP(ip).CheckError(); err != nil {
+					t.Errorf("R: %v", err)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+// Run with -race flag: the default client is replaced while package level
+// functions are used.
+func TestConcurrentDefault(t *testing.T) {
+	keepDefault(t)
+	clients := []*Client{
+		newFakeClient(t, tFakeTransport(echoJSON)),
+		newFakeClient(t, tFakeTransport(echoJSON)),
+	}
+	SetDefault(clients[0])
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				if g%4 == 0 {
+					SetDefault(clients[i%2])
+					continue
+				}
+				if c := Default(); c != clients[0] && c != clients[1] {
+					t.Errorf("Default: unexpected client %p", c)
+				}
+				if r, err := IP("1.1.1.1"); err != nil || r.IP != "1.1.1.1" {
+					t.Errorf("IP: %v, %+v", err, r)
+				}
+				if rs, err := IPs("1.1.1.1", "2.2.2.2"); err != nil || len(rs) != 2 {
+					t.Errorf("IPs: %v, %d items", err, len(rs))
+				}
+				if _, err := Me(); err != nil {
+					t.Errorf("Me: %v", err)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+func TestDefaultClientIsWriteOnly(t *testing.T) {
+	keepDefault(t)
+	c := newFakeClient(t, tFakeTransport(echoJSON))
+	SetDefault(c)
+	if DefaultClient != c {
+		t.Fatal("SetDefault: legacy alias isn't updated")
+	}
+	// Assigning the legacy alias doesn't change the default client
+	DefaultClient = nil
+	if Default() != c {
+		t.Fatal("Default: the legacy alias has been read")
+	}
+	SetDefault(nil)
+	if _, err := IP("1.1.1.1"); !errors.Is(err, ErrNoDefaultClient) {
+		t.Fatalf("IP: got %v, expected %v", err, ErrNoDefaultClient)
+	}
+	// 'New' sets the default client only if there is no one
+	first := newFakeClient(t, tFakeTransport(echoJSON))
+	newFakeClient(t, tFakeTransport(echoJSON))
+	if Default() != first {
+		t.Fatal("New: the default client has been replaced")
+	}
+}
+
+func TestRequestDoesNotLeakIntoClient(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		queries []string
+	)
+	c := newFakeClient(t, tFakeTransport(func(req *http.Request) (int, string) {
+		mu.Lock()
+		queries = append(queries, req.URL.RawQuery)
+		mu.Unlock()
+		return echoJSON(req)
+	}), ParamFields(FieldCity, FieldCountryCode))
+	base := c.baseReq.reqArgsBuilt
+
+	derived := c.R().
+		Fields(FieldLocation).
+		WithoutFields(FieldCity).
+		Language(LangGerman).
+		Hostname(true).
+		EnableSecurity(true).
+		UseHTTPS(true).
+		Format(FormatXML)
+	if derived.reqArgsBuilt == base {
+		t.Fatal("derived request isn't changed")
+	}
+	if c.baseReq.reqArgsBuilt != base || "?"+c.baseReq.reqArgs.Encode() != base {
+		t.Fatalf("client's args are changed: %q, expected %q", c.baseReq.reqArgsBuilt, base)
+	}
+	c.R().ResetFields()
+	if c.baseReq.reqArgs.Get("fields") == "" || !c.baseReq.fields.Has(FieldCity) {
+		t.Fatal("ResetFields: client's fields are changed")
+	}
+
+	// Clones are independent of each other too
+	req := c.R().Fields(FieldZip)
+	reqArgs := req.reqArgsBuilt
+	clone := req.Clone().ResetFields().Language(LangSpanish)
+	if req.reqArgsBuilt != reqArgs || !req.fields.Has(FieldZip) {
+		t.Fatalf("Clone: original request is changed: %q, expected %q", req.reqArgsBuilt, reqArgs)
+	}
+	if clone.reqArgs.Get("fields") != "" || clone.reqArgs.Get("language") != string(LangSpanish) {
+		t.Fatalf("Clone: unexpected args %q", clone.reqArgsBuilt)
+	}
+
+	// Requests of client still use client's args
+	if _, err := c.IP("1.1.1.1"); err != nil {
+		t.Fatalf("IP: %v", err)
+	}
+	if got := "?" + queries[len(queries)-1]; got != base {
+		t.Fatalf("IP: got query %q, expected %q", got, base)
+	}
+}
+
+func TestHostnameAndSecurityFlags(t *testing.T) {
+	var query url.Values
+	c := newFakeClient(t, tFakeTransport(func(req *http.Request) (int, string) {
+		query = req.URL.Query()
+		return echoJSON(req)
+	}), ParamHostname(true), ParamEnableSecurity(true))
+
+	check := func(name, hostname, security string) {
+		t.Helper()
+		if got := query.Get("hostname"); got != hostname {
+			t.Errorf("%s: got hostname=%q, expected %q", name, got, hostname)
+		}
+		if got := query.Get("security"); got != security {
+			t.Errorf("%s: got security=%q, expected %q", name, got, security)
+		}
+	}
+	if _, err := c.IP("1.1.1.1"); err != nil {
+		t.Fatalf("IP: %v", err)
+	}
+	check("client", "1", "1")
+
+	if err := c.R().Hostname(false).IP("1.1.1.1").CheckError(); err != nil {
+		t.Fatalf("IP: %v", err)
+	}
+	check("without hostname", "", "1")
+
+	if err := c.R().EnableSecurity(false).IP("1.1.1.1").CheckError(); err != nil {
+		t.Fatalf("IP: %v", err)
+	}
+	check("without security", "1", "")
+
+	if err := c.R().Hostname(false).EnableSecurity(false).IP("1.1.1.1").CheckError(); err != nil {
+		t.Fatalf("IP: %v", err)
+	}
+	check("without both", "", "")
+
+	// Turning the flags off per request doesn't change the client
+	if _, err := c.IP("1.1.1.1"); err != nil {
+		t.Fatalf("IP: %v", err)
+	}
+	check("client again", "1", "1")
+}
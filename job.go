@@ -0,0 +1,336 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// 'Sink' is the destination of the results of bulk job ('Job' object).
+//
+// 'Put' is called for each result in the input order and never concurrently
+// (but see 'Job' docs about failed chunks).
+// 'Flush' is called after each completed chunk right before the checkpoint
+// will be saved. It must make all results that has been put earlier durable.
+// If 'Put' or 'Flush' returns an error, the job will be stopped.
+type Sink interface {
+	Put(res StreamResult) error
+	Flush() error
+}
+
+// 'Job' is the runner of bulk lookups of very large IP lists that can be
+// resumed after crash or stop.
+//
+// The IP list is looked up by chunks (see 'StreamBatchSize' parameter),
+// results are written to the 'Sink' in the input order, and after each
+// completed chunk the number of processed IP addresses is saved to the
+// checkpoint file. When the job is started again with the same checkpoint
+// file and the same IP list, already processed IP addresses are skipped
+// and willn't be requested again.
+//
+// The job is stopped at the first failed chunk (request error or API error,
+// like 104 usage_limit_reached). The failed chunk isn't written to the
+// 'Sink' and isn't checkpointed, so it will be requested again
+// when the job is resumed.
+// But chunks that are in-flight at this moment are already requested
+// (and paid), so they are completed and written to the 'Sink' (that's why
+// there might be a gap in the input order). The checkpoint keeps them
+// as already processed, so they willn't be requested again after resume.
+// Invalid IP addresses aren't the chunk failure: they are written
+// to the 'Sink' with an error as any other result.
+//
+// WARNING! If the process crashes between writing results to the 'Sink'
+// and saving the checkpoint, the results of the last chunk will be written
+// to the 'Sink' again after resume.
+type Job struct {
+	req        *Request
+	checkpoint string
+	sink       Sink
+	params     []tStreamParam
+}
+
+// 'tCheckpoint' is the internal private type that represents the content
+// of the checkpoint file of 'Job'.
+//
+// 'Processed' is the number of IP addresses from the beginning of the list
+// that have been processed. 'Done' are the ranges [from, to) of indexes
+// of IP addresses after 'Processed' that have been processed too
+// (chunks that have been completed after the failed one).
+type tCheckpoint struct {
+	Processed int       `json:"processed"`
+	Done      [][2]int  `json:"done,omitempty"`
+	Updated   time.Time `json:"updated"`
+}
+
+// 'skip' reports whether IP address at 'index' has been already processed.
+func (cp *tCheckpoint) skip(index int) bool {
+	if index < cp.Processed {
+		return true
+	}
+	for _, rng := range cp.Done {
+		if index >= rng[0] && index < rng[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// 'complete' marks IP addresses with indexes [from, to) as processed.
+// 'Processed' is advanced to the highest contiguous processed index,
+// and ranges that are reached by it are removed from 'Done'.
+func (cp *tCheckpoint) complete(from, to int) {
+	cp.Done = append(cp.Done, [2]int{from, to})
+	sort.Slice(cp.Done, func(i, j int) bool { return cp.Done[i][0] < cp.Done[j][0] })
+	done := cp.Done[:0]
+	for _, rng := range cp.Done {
+		switch {
+		case rng[0] > cp.Processed:
+			done = append(done, rng)
+		case rng[1] > cp.Processed:
+			cp.Processed = rng[1]
+		}
+	}
+	if cp.Done = done; len(cp.Done) == 0 {
+		cp.Done = nil
+	}
+}
+
+// 'Job' is the same as 'Job' of 'Request' object that is got
+// by 'R' method. See 'Request.Job' docs for details.
+func (c *Client) Job(checkpoint string, sink Sink, params ...tStreamParam) *Job {
+	return c.R().Job(checkpoint, sink, params...)
+}
+
+// 'Job' creates a new 'Job' object that will look up IP addresses
+// using the current 'Request' object, will write results to the 'sink'
+// and will save the progress to the 'checkpoint' file.
+//
+// You can pass the same parameters as to 'Stream' method.
+// But results are always delivered to the 'sink' in the input order.
+func (r *Request) Job(checkpoint string, sink Sink, params ...tStreamParam) *Job {
+	return &Job{
+		req:        r.copy(),
+		checkpoint: checkpoint,
+		sink:       sink,
+		params:     params,
+	}
+}
+
+// 'Run' reads IP addresses from 'src' (the same way as 'Stream' do it),
+// skips already processed IP addresses (if checkpoint file exists)
+// and looks up the rest.
+//
+// It returns nil only if the whole 'src' has been processed.
+// Otherwise it returns the error of the failed chunk, 'Sink' error,
+// checkpoint saving error or the error of 'ctx'.
+//
+// NOTE! You must pass the same IP list each time the job is resumed.
+// The checkpoint file contains only the number of processed IP addresses.
+func (j *Job) Run(ctx context.Context, src io.Reader) error {
+	if j == nil {
+		return &OpError{Op: OpJob, Err: ErrNilJob}
+	}
+	if j.sink == nil {
+		return &OpError{Op: OpJob, Err: ErrNilSink}
+	}
+	if err := j.req.validate(); err != nil {
+		return &OpError{Op: OpJob, Err: err}
+	}
+	if src == nil {
+		return &OpError{Op: OpJob, Err: ErrNilSource}
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cp, err := j.load()
+	if err != nil {
+		return err
+	}
+	cfg := tStreamConfig{
+		workers:   cStreamDefaultWorkers,
+		batchSize: cStreamDefaultBatchSize,
+	}
+	for _, param := range j.params {
+		if param != nil {
+			param(&cfg)
+		}
+	}
+	// Source is read concurrently with saving the progress,
+	// so skipped IP addresses are checked by the loaded checkpoint
+	loaded := cp
+	loaded.Done = append([][2]int(nil), cp.Done...)
+	cfg.ordered = true
+	cfg.skip = loaded.skip
+	cfg.drain = true
+	// Failed chunks aren't written and checkpointed. The first failure stops
+	// reading of source, but in-flight chunks are completed and saved.
+	var failed error
+	err = j.req.stream(ctx, src, cfg, func(c *tStreamChunk) error {
+		if c.err != nil {
+			if failed == nil {
+				failed = c.err
+			}
+			return nil
+		}
+		for _, item := range c.items {
+			if err := j.sink.Put(item); err != nil {
+				return &OpError{Op: OpJob, Err: fmt.Errorf("Sink error: %w", err)}
+			}
+		}
+		if err := j.sink.Flush(); err != nil {
+			return &OpError{Op: OpJob, Err: fmt.Errorf("Sink error: %w", err)}
+		}
+		cp.complete(c.items[0].Index, c.items[len(c.items)-1].Index+1)
+		return j.save(cp)
+	})
+	if err == nil {
+		err = failed
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+	return err
+}
+
+// 'Progress' returns the number of already processed IP addresses
+// from the beginning of the list as it saved in the checkpoint file.
+// If checkpoint file doesn't exist, 0 is returned.
+//
+// NOTE! IP addresses that have been processed after the failed chunk
+// aren't counted until the failed chunk will be processed.
+func (j *Job) Progress() (int, error) {
+	if j == nil {
+		return 0, &OpError{Op: OpJob, Err: ErrNilJob}
+	}
+	cp, err := j.load()
+	return cp.Processed, err
+}
+
+// 'load' is the internal private auxiliary method of 'Job' class.
+// It reads the checkpoint file. If it doesn't exist,
+// the empty checkpoint is returned.
+func (j *Job) load() (tCheckpoint, error) {
+	cp := tCheckpoint{}
+	b, err := ioutil.ReadFile(j.checkpoint)
+	if os.IsNotExist(err) {
+		return cp, nil
+	}
+	if err != nil {
+		return cp, &OpError{Op: OpJob, Err: fmt.Errorf("Error reading checkpoint: %w", err)}
+	}
+	if err := json.Unmarshal(b, &cp); err != nil {
+		return cp, &OpError{Op: OpJob, Err: fmt.Errorf("Malformed checkpoint: %w", err)}
+	}
+	return cp, nil
+}
+
+// 'save' is the internal private auxiliary method of 'Job' class.
+// It saves 'cp' to the checkpoint file.
+//
+// The checkpoint is written to the temporary file first, and then
+// that file is renamed. So, the checkpoint file is never corrupted,
+// even if the process crashes while it's written.
+func (j *Job) save(cp tCheckpoint) error {
+	cp.Updated = time.Now()
+	b, err := json.Marshal(cp)
+	if err != nil {
+		return &OpError{Op: OpJob, Err: fmt.Errorf("Error saving checkpoint: %w", err)}
+	}
+	f, err := ioutil.TempFile(filepath.Dir(j.checkpoint), filepath.Base(j.checkpoint)+".tmp")
+	if err != nil {
+		return &OpError{Op: OpJob, Err: fmt.Errorf("Error saving checkpoint: %w", err)}
+	}
+	_, err = f.Write(b)
+	if err == nil {
+		err = f.Sync()
+	}
+	if errClose := f.Close(); err == nil {
+		err = errClose
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), j.checkpoint)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return &OpError{Op: OpJob, Err: fmt.Errorf("Error saving checkpoint: %w", err)}
+	}
+	return nil
+}
+
+// 'tJSONLinesSink' is the internal private type that implements 'Sink'
+// interface and writes each result as one line of JSON.
+type tJSONLinesSink struct {
+	w   io.Writer
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+// 'tJSONLine' is the internal private type that represents
+// one line written by 'tJSONLinesSink'.
+type tJSONLine struct {
+	Index    int       `json:"index"`
+	IP       string    `json:"ip"`
+	Response *Response `json:"response,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// 'JSONLinesSink' returns the 'Sink' that writes each result to 'w'
+// as one line of JSON: "{"index": N, "ip": "...", "response": {...}}"
+// or "{"index": N, "ip": "...", "error": "..."}".
+//
+// Results are buffered and written to 'w' when 'Flush' is called.
+// If 'w' has 'Sync' method (like *os.File), it's called too.
+func JSONLinesSink(w io.Writer) Sink {
+	buf := bufio.NewWriter(w)
+	return &tJSONLinesSink{w: w, buf: buf, enc: json.NewEncoder(buf)}
+}
+
+// 'Put' implements the 'Sink' interface for 'tJSONLinesSink' class.
+func (s *tJSONLinesSink) Put(res StreamResult) error {
+	line := tJSONLine{Index: res.Index, IP: res.IP, Response: res.Response}
+	if res.Error != nil {
+		line.Error = res.Error.Error()
+	}
+	return s.enc.Encode(&line)
+}
+
+// 'Flush' implements the 'Sink' interface for 'tJSONLinesSink' class.
+func (s *tJSONLinesSink) Flush() error {
+	if err := s.buf.Flush(); err != nil {
+		return err
+	}
+	if syncer, ok := s.w.(interface{ Sync() error }); ok {
+		return syncer.Sync()
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 'usageLimitJSON' is the Web API error 104 usage_limit_reached.
+const usageLimitJSON = `{"success":false,"error":{"code":104,"type":"usage_limit_reached","info":"limit"}}`
+
+// 'tMemorySink' is the 'Sink' that keeps indexes of put results.
+type tMemorySink struct {
+	indexes []int
+	flushes int
+}
+
+func (s *tMemorySink) Put(res StreamResult) error {
+	s.indexes = append(s.indexes, res.Index)
+	return nil
+}
+
+func (s *tMemorySink) Flush() error {
+	s.flushes++
+	return nil
+}
+
+// 'tRecorder' records queried IP addresses of fake transport.
+type tRecorder struct {
+	mu  sync.Mutex
+	ips []string
+}
+
+func (r *tRecorder) record(req *http.Request) {
+	r.mu.Lock()
+	r.ips = append(r.ips, queriedIPs(req)...)
+	r.mu.Unlock()
+}
+
+func TestJobDrainsInFlightChunksOnFailure(t *testing.T) {
+	src := "1.0.0.0\n1.0.0.1\n1.0.0.2\n1.0.0.3\n1.0.0.4\n1.0.0.5\n1.0.0.6\n1.0.0.7\n1.0.0.8\n1.0.0.9\n"
+	checkpoint := filepath.Join(t.TempDir(), "job.checkpoint")
+	params := []tStreamParam{StreamWorkers(4), StreamBatchSize(1)}
+
+	// The third IP address fails, while three others are in-flight
+	first := &tRecorder{}
+	c := newFakeClient(t, tFakeTransport(func(req *http.Request) (int, string) {
+		first.record(req)
+		if queriedIPs(req)[0] == "1.0.0.2" {
+			time.Sleep(10 * time.Millisecond)
+			return http.StatusOK, usageLimitJSON
+		}
+		time.Sleep(50 * time.Millisecond)
+		return echoJSON(req)
+	}))
+	sink := &tMemorySink{}
+	err := c.Job(checkpoint, sink, params...).Run(context.Background(), strings.NewReader(src))
+	if !errors.Is(err, ErrUsageLimitReached) {
+		t.Fatalf("Run: got %v, expected usage limit error", err)
+	}
+	if want := []int{0, 1, 3}; !reflect.DeepEqual(sink.indexes, want) {
+		t.Fatalf("Run: sink got %v, expected %v (requested %v)", sink.indexes, want, first.ips)
+	}
+	if n, err := c.Job(checkpoint, sink).Progress(); err != nil || n != 2 {
+		t.Fatalf("Progress: got %d, %v, expected 2", n, err)
+	}
+
+	// Resume: only the failed chunk and not requested IP addresses
+	// must be requested
+	second := &tRecorder{}
+	c = newFakeClient(t, tFakeTransport(func(req *http.Request) (int, string) {
+		second.record(req)
+		return echoJSON(req)
+	}))
+	sink = &tMemorySink{}
+	if err := c.Job(checkpoint, sink, params...).Run(context.Background(), strings.NewReader(src)); err != nil {
+		t.Fatalf("Run (resume): %v", err)
+	}
+	if want := []int{2, 4, 5, 6, 7, 8, 9}; !reflect.DeepEqual(sink.indexes, want) {
+		t.Fatalf("Run (resume): sink got %v, expected %v", sink.indexes, want)
+	}
+	for _, ip := range second.ips {
+		if ip == "1.0.0.0" || ip == "1.0.0.1" || ip == "1.0.0.3" {
+			t.Fatalf("Run (resume): %s is requested again", ip)
+		}
+	}
+	if n, err := c.Job(checkpoint, sink).Progress(); err != nil || n != 10 {
+		t.Fatalf("Progress: got %d, %v, expected 10", n, err)
+	}
+}
+
+// 'tFailingSink' is the 'Sink' that fails with 'err'.
+type tFailingSink struct{ err error }
+
+func (s tFailingSink) Put(StreamResult) error { return s.err }
+func (s tFailingSink) Flush() error           { return s.err }
+
+func TestJobWrapsCauses(t *testing.T) {
+	c := newFakeClient(t, tFakeTransport(echoJSON))
+	src := "1.1.1.1\n"
+	dir := t.TempDir()
+	isJobError := func(err error) bool {
+		var opErr *OpError
+		return errors.As(err, &opErr) && opErr.Op == OpJob
+	}
+
+	errSink := errors.New("disk is full")
+	err := c.Job(filepath.Join(dir, "sink"), tFailingSink{errSink}).
+		Run(context.Background(), strings.NewReader(src))
+	if !errors.Is(err, errSink) || !isJobError(err) {
+		t.Errorf("sink: got %v, expected wrapped sink error", err)
+	}
+
+	err = c.Job(filepath.Join(dir, "missing", "job"), &tMemorySink{}).
+		Run(context.Background(), strings.NewReader(src))
+	if !errors.Is(err, os.ErrNotExist) || !isJobError(err) {
+		t.Errorf("save: got %v, expected wrapped os.ErrNotExist", err)
+	}
+
+	malformed := filepath.Join(dir, "malformed")
+	if err := os.WriteFile(malformed, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Job(malformed, &tMemorySink{}).Progress()
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) || !isJobError(err) {
+		t.Errorf("load: got %v, expected wrapped JSON syntax error", err)
+	}
+}
+
+func TestCheckpointComplete(t *testing.T) {
+	cp := tCheckpoint{Processed: 2}
+	cp.complete(5, 7)
+	cp.complete(3, 4)
+	if cp.Processed != 2 || len(cp.Done) != 2 || !cp.skip(3) || cp.skip(4) || !cp.skip(6) {
+		t.Fatalf("got %+v", cp)
+	}
+	cp.complete(2, 3)
+	cp.complete(4, 5)
+	if cp.Processed != 7 || cp.Done != nil {
+		t.Fatalf("got %+v, expected all completed up to 7", cp)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 'Lang' is the language of localized names (country, region, city, etc.)
+// in Web API response. Use predefined consts started with 'Lang...' prefix.
+type Lang string
+
+// Predefined consts each of that represents some language
+// supported by ipstack.
+const (
+	LangEnglish             Lang = "en"
+	LangGerman              Lang = "de"
+	LangSpanish             Lang = "es"
+	LangFrench              Lang = "fr"
+	LangJapanese            Lang = "ja"
+	LangPortugueseBrazilian Lang = "pt-br"
+	LangRussian             Lang = "ru"
+	LangChinese             Lang = "zh"
+)
+
+// 'allLangs' is the set of all supported languages.
+var allLangs = map[Lang]bool{
+	LangEnglish: true, LangGerman: true, LangSpanish: true, LangFrench: true,
+	LangJapanese: true, LangPortugueseBrazilian: true, LangRussian: true,
+	LangChinese: true,
+}
+
+// 'Language' specifies the language of localized names in Web API response.
+// The language code is case-insensitive ("pt-BR" is the same as "pt-br").
+// Empty 'lang' means the default language (English).
+//
+// WARNING! Unsupported languages are rejected. The error (wraps
+// 'ErrUnknownLanguage') is saved to the current object and will be returned
+// by each request ('IP', 'IPs', 'Me') w/o performing it.
+func (r *Request) Language(lang Lang) *Request {
+	if r == nil {
+		return nil
+	}
+	lang = Lang(strings.ToLower(strings.TrimSpace(string(lang))))
+	if lang != "" && !allLangs[lang] {
+		if r.err == nil {
+			r.err = fmt.Errorf("%w: %q", ErrUnknownLanguage, string(lang))
+		}
+		return r
+	}
+	r.lang = lang
+	return r.setArg("language", string(lang))
+}
+
+// 'ParamLanguage' creates a parameter for 'Client' constructors that
+// specifies the language of localized names in Web API response.
+//
+// WARNING! Unsupported languages are rejected and 'Client' constructor returns
+// an error (wraps 'ErrUnknownLanguage').
+func ParamLanguage(lang Lang) tClientParam {
+	return func(c *Client) {
+		if c != nil {
+			c.noteParam("language", strings.ToLower(strings.TrimSpace(string(lang))))
+			c.baseReq = c.baseReq.Language(lang)
+		}
+	}
+}
+
+// 'MeIn' is the same as 'Me' but returns the info about your IP address
+// in the language 'lang'. The info is cached per language, so the info
+// in each language is fetched only once (unless 'forceFetch' is true).
+//
+// WARNING! Unsupported language is rejected with 'ErrUnknownLanguage' error.
+func (c *Client) MeIn(lang Lang, forceFetch ...bool) (*Response, error) {
+	if err := c.validate(); err != nil {
+		return nil, &OpError{Op: OpMe, Err: err}
+	}
+	req := c.baseReq.copy().Language(lang)
+	if err := req.validate(); err != nil {
+		return nil, &OpError{Op: OpMe, Err: err}
+	}
+	return c.me(req, len(forceFetch) > 0 && forceFetch[0])
+}
+
+// 'Language' returns the language the current object has been fetched in
+// ('LangEnglish' if it hasn't been specified).
+func (r *Response) Language() Lang {
+	if r == nil || r.lang == "" {
+		return LangEnglish
+	}
+	return r.lang
+}
+
+// 'setLang' saves 'lang' to the decoded 'Response' objects of 'dst'.
+// Other types of 'dst' are ignored.
+func setLang(dst interface{}, lang Lang) {
+	switch d := dst.(type) {
+	case *Response:
+		if d != nil {
+			d.lang = lang
+		}
+	case **Response:
+		if d != nil && *d != nil {
+			(*d).lang = lang
+		}
+	case *[]*Response:
+		if d != nil {
+			for _, res := range *d {
+				if res != nil {
+					res.lang = lang
+				}
+			}
+		}
+	case *[]Response:
+		if d != nil {
+			for i := range *d {
+				(*d)[i].lang = lang
+			}
+		}
+	}
+}
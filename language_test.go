@@ -0,0 +1,89 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// 'localizedMe' answers the 'Me' request by the country name
+// in the requested language and counts requests.
+func localizedMe(calls *int) tFakeTransport {
+	return func(req *http.Request) (int, string) {
+		*calls++
+		lang := req.URL.Query().Get("language")
+		return http.StatusOK, fmt.Sprintf(`{"ip":"9.9.9.9","country_name":"country-%s"}`, lang)
+	}
+}
+
+func TestMeIsCachedPerLanguage(t *testing.T) {
+	calls := 0
+	c := newFakeClient(t, localizedMe(&calls), ParamLanguage(LangGerman))
+	me, err := c.Me()
+	if err != nil || me.CountryName != "country-de" || me.Language() != LangGerman {
+		t.Fatalf("Me: got %+v, %v", me, err)
+	}
+	ja, err := c.MeIn(LangJapanese)
+	if err != nil || ja.CountryName != "country-ja" || ja.Language() != LangJapanese {
+		t.Fatalf("MeIn(ja): got %+v, %v", ja, err)
+	}
+	// Both are cached now and don't replace each other
+	if again, _ := c.Me(); again != me {
+		t.Fatalf("Me: cached German info is replaced")
+	}
+	if again, _ := c.MeIn(LangJapanese); again != ja {
+		t.Fatalf("MeIn(ja): cached Japanese info is replaced")
+	}
+	if again, _ := c.MeIn("DE"); again != me {
+		t.Fatalf("MeIn(DE): cached German info isn't used")
+	}
+	if calls != 2 {
+		t.Fatalf("%d requests performed, expected 2", calls)
+	}
+	if _, err := c.MeIn("xx"); !errors.Is(err, ErrUnknownLanguage) {
+		t.Fatalf("MeIn(xx): got %v, expected unknown language error", err)
+	}
+	// A request in other language doesn't touch the cache
+	if rr := c.R().Language(LangFrench).Me(); rr.Error != nil {
+		t.Fatalf("Request.Me: %v", rr.Error)
+	}
+	if again, _ := c.Me(); again != me || calls != 3 {
+		t.Fatalf("Me: cache is changed by request in other language")
+	}
+}
+
+func TestMeDefaultLanguageIsEnglish(t *testing.T) {
+	calls := 0
+	c := newFakeClient(t, localizedMe(&calls))
+	me, err := c.Me()
+	if err != nil {
+		t.Fatalf("Me: %v", err)
+	}
+	if en, _ := c.MeIn(LangEnglish); en != me || calls != 1 {
+		t.Fatalf("MeIn(en): cached info isn't used, %d requests", calls)
+	}
+}
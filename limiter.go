@@ -0,0 +1,215 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Tuning of 'AdaptiveLimiter' behaviour.
+const (
+	// The limit is multiplied by that value when request is throttled
+	cLimiterErrorBackoff float64 = 0.5
+	// The limit is multiplied by that value when latency is too high
+	cLimiterLatencyBackoff float64 = 0.9
+	// Latency is too high if it's that times more than the baseline latency
+	cLimiterLatencyTolerance float64 = 2
+	// How fast the baseline latency follows the growing latency
+	cLimiterBaselineDrift float64 = 0.01
+)
+
+// 'AdaptiveLimiter' is the AIMD (additive increase, multiplicative decrease)
+// controller of how much requests can be performed at the same time.
+//
+// While requests are successful and their latency is close to the baseline
+// (the lowest observed latency), the limit grows by 1 per each 'limit'
+// successful requests. When request is throttled (see 'isThrottling')
+// the limit is halved, and when latency grows, the limit is
+// decreased slightly. The limit is always in the ['min', 'max'] range.
+//
+// Other errors (cancellation, invalid IP, auth errors, etc) say nothing
+// about the load of Web API, so they don't change the limit.
+//
+// Pass it to the streaming lookups or jobs using 'StreamAdaptive' parameter.
+// The same 'AdaptiveLimiter' object can be shared between a few streams
+// or jobs, then it limits all of them together.
+//
+// WARNING! Do not create this object directly!
+// Use 'NewAdaptiveLimiter' function instead.
+type AdaptiveLimiter struct {
+	mu          sync.Mutex
+	min         int
+	max         int
+	limit       float64
+	inFlight    int
+	wake        chan struct{}
+	baseline    time.Duration
+	lastBackoff time.Time
+}
+
+// 'NewAdaptiveLimiter' creates a new 'AdaptiveLimiter' object that starts
+// from 'min' requests at the same time and never exceeds 'max'.
+// Values of 'min' less than 1 are treated as 1, and 'max' less than 'min'
+// is treated as 'min'.
+func NewAdaptiveLimiter(min, max int) *AdaptiveLimiter {
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+	return &AdaptiveLimiter{
+		min:   min,
+		max:   max,
+		limit: float64(min),
+		wake:  make(chan struct{}),
+	}
+}
+
+// 'Limit' returns how much requests can be performed at the same time
+// right now.
+func (l *AdaptiveLimiter) Limit() int {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+// 'InFlight' returns how much requests are performing right now.
+func (l *AdaptiveLimiter) InFlight() int {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inFlight
+}
+
+// 'Acquire' waits until one more request can be performed
+// (or until 'ctx' is done) and returns the function that must be called
+// with the request error (or nil) right after the request is completed.
+// The latency of request is measured between these two calls.
+//
+// If 'ctx' is done before request can be performed, the error of 'ctx'
+// is returned.
+func (l *AdaptiveLimiter) Acquire(ctx context.Context) (release func(err error), err error) {
+	if l == nil {
+		return func(error) {}, nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for {
+		l.mu.Lock()
+		if l.inFlight < int(l.limit) {
+			l.inFlight++
+			l.mu.Unlock()
+			break
+		}
+		wake := l.wake
+		l.mu.Unlock()
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	start := time.Now()
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() { l.release(start, err) })
+	}, nil
+}
+
+// 'release' is the internal private auxiliary method of 'AdaptiveLimiter'.
+// It updates the limit depending on the result of request started at
+// 'start' and wakes up all waiters of 'Acquire'.
+func (l *AdaptiveLimiter) release(start time.Time, err error) {
+	latency := time.Since(start)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	switch {
+	case err != nil && isThrottling(err):
+		l.backoff(start, cLimiterErrorBackoff)
+	case err != nil:
+		// Neutral, the limit isn't changed
+	case l.baseline == 0 || latency < l.baseline:
+		l.baseline = latency
+		l.increase()
+	case float64(latency) > float64(l.baseline)*cLimiterLatencyTolerance:
+		l.baseline += time.Duration(float64(latency-l.baseline) * cLimiterBaselineDrift)
+		l.backoff(start, cLimiterLatencyBackoff)
+	default:
+		l.increase()
+	}
+	close(l.wake)
+	l.wake = make(chan struct{})
+}
+
+// 'increase' is the additive part of AIMD.
+// The limit grows by 1 per each 'limit' successful requests.
+func (l *AdaptiveLimiter) increase() {
+	if l.limit += 1 / l.limit; l.limit > float64(l.max) {
+		l.limit = float64(l.max)
+	}
+}
+
+// 'backoff' is the multiplicative part of AIMD.
+// Requests that has been started before the last backoff are ignored,
+// because they have been performed under the old limit. It prevents
+// collapsing the limit to the minimum when a lot of concurrent requests
+// fail at the same time.
+func (l *AdaptiveLimiter) backoff(start time.Time, factor float64) {
+	if start.Before(l.lastBackoff) {
+		return
+	}
+	l.lastBackoff = time.Now()
+	if l.limit *= factor; l.limit < float64(l.min) {
+		l.limit = float64(l.min)
+	}
+}
+
+// 'isThrottling' reports whether 'err' means that Web API throttles
+// requests: the usage limit is reached (104 usage_limit_reached)
+// or HTTP status is 429 Too Many Requests or 503 Service Unavailable.
+func isThrottling(err error) bool {
+	if findAPIErr(err).Code() == CodeUsageLimitReached {
+		return true
+	}
+	for ; err != nil; err = unwrap(err) {
+		if e, ok := err.(*OpError); ok {
+			switch e.StatusCode {
+			case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+				return true
+			}
+		}
+	}
+	return false
+}
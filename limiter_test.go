@@ -0,0 +1,160 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveLimiterBacksOffOnlyOnThrottling(t *testing.T) {
+	cases := []struct {
+		name    string
+		err     error
+		backoff bool
+	}{
+		{"canceled", &OpError{Op: OpIP, Err: context.Canceled}, false},
+		{"deadline", context.DeadlineExceeded, false},
+		{"invalid ip", &OpError{Op: OpIP, Err: ErrInvalidIP}, false},
+		{"auth", &OpError{Op: OpIP, StatusCode: http.StatusOK, Err: ErrInvalidAccessKey}, false},
+		{"usage limit", &OpError{Op: OpIP, Err: newAPIErr(CodeUsageLimitReached, "", "")}, true},
+		{"http 429", &OpError{Op: OpIP, StatusCode: http.StatusTooManyRequests, Err: fmt.Errorf("body")}, true},
+		{"http 503", &OpError{Op: OpIP, StatusCode: http.StatusServiceUnavailable, Err: fmt.Errorf("body")}, true},
+	}
+	for _, tc := range cases {
+		l := NewAdaptiveLimiter(1, 16)
+		l.limit = 8
+		release, err := l.Acquire(context.Background())
+		if err != nil {
+			t.Fatalf("%s: Acquire: %v", tc.name, err)
+		}
+		release(tc.err)
+		want := 8
+		if tc.backoff {
+			want = 4
+		}
+		if got := l.Limit(); got != want {
+			t.Errorf("%s: limit %d, expected %d", tc.name, got, want)
+		}
+		if l.InFlight() != 0 {
+			t.Errorf("%s: %d in-flight after release", tc.name, l.InFlight())
+		}
+	}
+}
+
+// 'finish' simulates the request performed under 'l' limiter
+// that took 'latency' and completed with 'err'.
+func finish(l *AdaptiveLimiter, latency time.Duration, err error) {
+	l.mu.Lock()
+	l.inFlight++
+	l.mu.Unlock()
+	l.release(time.Now().Add(-latency), err)
+}
+
+func TestAdaptiveLimiterIncreasesAdditively(t *testing.T) {
+	l := NewAdaptiveLimiter(1, 16)
+	finish(l, 10*time.Millisecond, nil)
+	if l.Limit() != 2 || l.baseline < 10*time.Millisecond {
+		t.Fatalf("first success: limit %d, baseline %v", l.Limit(), l.baseline)
+	}
+	// The limit grows by 1 per each 'limit' successful requests
+	l.limit, l.baseline = 4, 10*time.Millisecond
+	for i := 1; i <= 5; i++ {
+		finish(l, 10*time.Millisecond, nil)
+		want := 4
+		if i == 5 {
+			want = 5
+		}
+		if got := l.Limit(); got != want {
+			t.Fatalf("success %d: limit %d, expected %d", i, got, want)
+		}
+	}
+	// Errors that aren't throttling don't change the limit
+	finish(l, 0, &OpError{Op: OpIP, Err: ErrInvalidIP})
+	if got := l.Limit(); got != 5 {
+		t.Fatalf("neutral error: limit %d, expected 5", got)
+	}
+}
+
+func TestAdaptiveLimiterBacksOffOnLatency(t *testing.T) {
+	l := NewAdaptiveLimiter(1, 16)
+	l.limit, l.baseline = 10, 10*time.Millisecond
+	// Latency is within the tolerance
+	finish(l, 15*time.Millisecond, nil)
+	if got := l.Limit(); got != 10 {
+		t.Fatalf("tolerable latency: limit %d, expected 10", got)
+	}
+	l.limit = 10
+	started := time.Now().Add(-60 * time.Millisecond)
+	finish(l, 50*time.Millisecond, nil)
+	if got := l.Limit(); got != 9 {
+		t.Fatalf("high latency: limit %d, expected 9", got)
+	}
+	// The baseline slowly follows the growing latency
+	if l.baseline <= 10*time.Millisecond || l.baseline > 11*time.Millisecond {
+		t.Fatalf("high latency: baseline %v", l.baseline)
+	}
+	// The request started before the last backoff is ignored
+	l.mu.Lock()
+	l.inFlight++
+	l.mu.Unlock()
+	l.release(started, nil)
+	if got := l.Limit(); got != 9 {
+		t.Fatalf("old request: limit %d, expected 9", got)
+	}
+}
+
+func TestAdaptiveLimiterClamps(t *testing.T) {
+	l := NewAdaptiveLimiter(2, 3)
+	if got := l.Limit(); got != 2 {
+		t.Fatalf("NewAdaptiveLimiter: limit %d, expected 2", got)
+	}
+	l.baseline = 10 * time.Millisecond
+	for i := 0; i < 20; i++ {
+		finish(l, 10*time.Millisecond, nil)
+	}
+	if got := l.Limit(); got != 3 || l.limit != 3 {
+		t.Fatalf("max: limit %v, expected 3", l.limit)
+	}
+	throttled := &OpError{Op: OpIP, StatusCode: http.StatusTooManyRequests, Err: fmt.Errorf("body")}
+	for i := 0; i < 5; i++ {
+		finish(l, 0, throttled)
+	}
+	if got := l.Limit(); got != 2 || l.limit != 2 {
+		t.Fatalf("min: limit %v, expected 2", l.limit)
+	}
+
+	// Invalid bounds are fixed by constructor
+	l = NewAdaptiveLimiter(0, -1)
+	if l.min != 1 || l.max != 1 || l.Limit() != 1 {
+		t.Fatalf("NewAdaptiveLimiter(0, -1): min %d, max %d, limit %d", l.min, l.max, l.Limit())
+	}
+	l = NewAdaptiveLimiter(4, 2)
+	if l.min != 4 || l.max != 4 {
+		t.Fatalf("NewAdaptiveLimiter(4, 2): min %d, max %d", l.min, l.max)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"context"
+)
+
+// 'Lookup' performs 'IP' request using 'r' bound to 'ctx', checks Web API
+// error and decodes the response to the new object of 'T' type.
+// It's the same pipeline as 'IP' method of 'Client' class does,
+// but for any type you want: your own struct with only fields you need,
+// 'map[string]any', 'json.RawMessage', etc.
+//
+// 'r' isn't changed, 'ctx' is used only for this request.
+// If 'ctx' is nil, the context of 'r' is used (see 'WithContext').
+//
+// NOTE! Fields of 'r' are requested. Use 'FieldsOf' and 'WithFieldSet'
+// of 'Request' class to request only fields of 'T' struct.
+func Lookup[T any](ctx context.Context, r *Request, ip string) (T, error) {
+	var res T
+	if err := withContext(ctx, r).IP(ip).decode(&res); err != nil {
+		var zero T
+		return zero, err
+	}
+	return res, nil
+}
+
+// 'LookupMany' is the same as 'Lookup' but performs 'IPs' request
+// and decodes the response to the slice of 'T' objects
+// (one per each valid passed IP address).
+func LookupMany[T any](ctx context.Context, r *Request, ips ...string) ([]T, error) {
+	var res []T
+	if err := withContext(ctx, r).IPs(ips...).decode(&res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// 'withContext' returns the copy of 'r' bound to 'ctx',
+// or 'r' itself if 'r' or 'ctx' is nil.
+func withContext(ctx context.Context, r *Request) *Request {
+	if r == nil || ctx == nil {
+		return r
+	}
+	return r.copy().WithContext(ctx)
+}
@@ -0,0 +1,157 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type tLookupKey struct{}
+
+type tLookupCity struct {
+	IP          string `json:"ip"`
+	CountryCode string `json:"country_code"`
+}
+
+// 'contextRecorder' returns the fake transport that answers by 'echoJSON'
+// and saves the value of 'tLookupKey' of request's context to 'got'.
+func contextRecorder(mu *sync.Mutex, got *interface{}) tFakeTransport {
+	return func(req *http.Request) (int, string) {
+		mu.Lock()
+		*got = req.Context().Value(tLookupKey{})
+		mu.Unlock()
+		return echoJSON(req)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	c := newFakeClient(t, tFakeTransport(echoJSON))
+
+	city, err := Lookup[tLookupCity](context.Background(), c.R(), "1.1.1.1")
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if city != (tLookupCity{IP: "1.1.1.1", CountryCode: "US"}) {
+		t.Fatalf("Lookup: got %+v", city)
+	}
+
+	m, err := Lookup[map[string]interface{}](context.Background(), c.R(), "2.2.2.2")
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if want := map[string]interface{}{"ip": "2.2.2.2", "country_code": "US"}; !reflect.DeepEqual(m, want) {
+		t.Fatalf("Lookup: got %v, expected %v", m, want)
+	}
+
+	if _, err := Lookup[tLookupCity](context.Background(), c.R(), "bogus"); !errors.Is(err, ErrInvalidIP) {
+		t.Fatalf("Lookup: got %v, expected ErrInvalidIP", err)
+	}
+}
+
+func TestLookupAPIError(t *testing.T) {
+	c := newFakeClient(t, replyWith(usageLimitJSON))
+	city, err := Lookup[tLookupCity](context.Background(), c.R(), "1.1.1.1")
+	if !errors.Is(err, ErrUsageLimitReached) {
+		t.Fatalf("Lookup: got %v, expected ErrUsageLimitReached", err)
+	}
+	if city != (tLookupCity{}) {
+		t.Fatalf("Lookup: got %+v, expected zero value", city)
+	}
+	cities, err := LookupMany[tLookupCity](nil, c.R(), "1.1.1.1", "2.2.2.2")
+	if !errors.Is(err, ErrUsageLimitReached) || cities != nil {
+		t.Fatalf("LookupMany: got %v, %v", cities, err)
+	}
+}
+
+func TestLookupContext(t *testing.T) {
+	var (
+		mu  sync.Mutex
+		got interface{}
+	)
+	c := newFakeClient(t, contextRecorder(&mu, &got))
+	bound := context.WithValue(context.Background(), tLookupKey{}, "request")
+	r := c.R().WithContext(bound)
+
+	// Nil context: the context of request is used
+	if _, err := Lookup[tLookupCity](nil, r, "1.1.1.1"); err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if got != "request" {
+		t.Fatalf("Lookup(nil): got context value %v, expected the request's one", got)
+	}
+
+	// Passed context is used only for this request
+	ctx := context.WithValue(context.Background(), tLookupKey{}, "lookup")
+	if _, err := LookupMany[tLookupCity](ctx, r, "1.1.1.1", "2.2.2.2"); err != nil {
+		t.Fatalf("LookupMany: %v", err)
+	}
+	if got != "lookup" {
+		t.Fatalf("LookupMany(ctx): got context value %v", got)
+	}
+	if r.ctx != bound {
+		t.Fatal("LookupMany: request's context is changed")
+	}
+
+	if _, err := Lookup[tLookupCity](nil, c.R(), "1.1.1.1"); err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if got != nil {
+		t.Fatalf("Lookup(nil): got context value %v", got)
+	}
+}
+
+func TestLookupManyDuplicates(t *testing.T) {
+	var queried []string
+	c := newFakeClient(t, tFakeTransport(func(req *http.Request) (int, string) {
+		queried = queriedIPs(req)
+		return echoJSON(req)
+	}))
+	ips := []string{"1.1.1.1", "2.2.2.2", "1.1.1.1", "bogus", "2.2.2.2"}
+
+	cities, err := LookupMany[tLookupCity](nil, c.R(), ips...)
+	if err != nil {
+		t.Fatalf("LookupMany: %v", err)
+	}
+	if strings.Join(queried, ",") != "1.1.1.1,2.2.2.2" {
+		t.Fatalf("LookupMany: queried %v", queried)
+	}
+	var got []string
+	for _, city := range cities {
+		got = append(got, city.IP)
+	}
+	if want := []string{"1.1.1.1", "2.2.2.2", "1.1.1.1", "2.2.2.2"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("LookupMany: got %v, expected %v", got, want)
+	}
+
+	maps, err := LookupMany[map[string]interface{}](nil, c.R(), "3.3.3.3", "3.3.3.3")
+	if err != nil || len(maps) != 2 || maps[0]["ip"] != "3.3.3.3" || maps[1]["ip"] != "3.3.3.3" {
+		t.Fatalf("LookupMany: got %v, %v", maps, err)
+	}
+}
@@ -0,0 +1,424 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// 'tResponseFields' is the internal private type that has the same fields
+// as 'Response' but has no methods. It used to encode and decode
+// declared fields of 'Response' w/o recursion.
+type tResponseFields Response
+
+// 'responseKeys' is the set of names of JSON fields declared by 'Response'.
+var responseKeys = jsonKeysOf(reflect.TypeOf(Response{}))
+
+// 'cZoneKey' is the name of JSON field the zone of requested IP address
+// is encoded to (see 'Addr' method of 'Response').
+// Web API never returns it, it's used only to make the round-trip lossless.
+const cZoneKey string = "ip_zone"
+
+// 'jsonKeysOf' returns the set of names of JSON fields of 't' struct.
+func jsonKeysOf(t reflect.Type) map[string]bool {
+	keys := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, skip := jsonFieldName(t.Field(i))
+		if !skip && name != "" {
+			keys[name] = true
+		}
+	}
+	return keys
+}
+
+// 'UnmarshalJSON' implements the 'json.Unmarshaler' interface
+// for 'Response' class.
+// It decodes declared fields as usual, saves the copy of 'data' to 'Raw'
+// and all undeclared fields to 'Extra'.
+func (r *Response) UnmarshalJSON(data []byte) error {
+	return r.decodeJSON(defaultCodec, data)
+}
+
+// 'decodeJSON' implements the 'tCodecDecoder' interface
+// for 'Response' class. It's the same as 'UnmarshalJSON' but uses 'codec'
+// (for nested 'TimeZone' and 'Security' objects too).
+func (r *Response) decodeJSON(codec Codec, data []byte) error {
+	if isJSONNull(data) {
+		return nil
+	}
+	aux := struct {
+		*tResponseFields
+		Timezone json.RawMessage `json:"time_zone"`
+		Security json.RawMessage `json:"security"`
+	}{tResponseFields: (*tResponseFields)(r)}
+	if err := codec.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if aux.Timezone != nil {
+		r.Timezone = nil
+		if !isJSONNull(aux.Timezone) {
+			r.Timezone = &TimeZone{}
+			if err := r.Timezone.decodeJSON(codec, aux.Timezone); err != nil {
+				return err
+			}
+		}
+	}
+	if aux.Security != nil {
+		r.Security = nil
+		if !isJSONNull(aux.Security) {
+			r.Security = &Security{}
+			if err := r.Security.decodeJSON(codec, aux.Security); err != nil {
+				return err
+			}
+		}
+	}
+	var all map[string]json.RawMessage
+	if err := codec.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	r.Raw = append(json.RawMessage(nil), data...)
+	r.Extra = nil
+	for k, v := range all {
+		if !responseKeys[k] && k != cZoneKey {
+			if r.Extra == nil {
+				r.Extra = make(map[string]json.RawMessage)
+			}
+			r.Extra[k] = v
+		}
+	}
+	r.zone = ""
+	if v, ok := all[cZoneKey]; ok {
+		_ = codec.Unmarshal(v, &r.zone)
+	}
+	r.present = presentFields(codec, all, "")
+	// Coordinates are decoded to 'float32' fields for compatibility,
+	// but here they are decoded with full precision
+	r.lat, r.lon = 0, 0
+	if v, ok := all["latitude"]; ok {
+		_ = codec.Unmarshal(v, &r.lat)
+	}
+	if v, ok := all["longitude"]; ok {
+		_ = codec.Unmarshal(v, &r.lon)
+	}
+	return nil
+}
+
+// 'Lat' returns the latitude associated with the IP with full precision.
+//
+// NOTE! If you have changed 'Latitide' field after decoding
+// (or the current object hasn't been decoded from JSON),
+// the value of that field is returned.
+func (r *Response) Lat() float64 {
+	if r == nil {
+		return 0
+	}
+	return fullPrecision(r.lat, r.Latitide)
+}
+
+// 'Lon' returns the longitude associated with the IP with full precision.
+//
+// NOTE! If you have changed 'Longitude' field after decoding
+// (or the current object hasn't been decoded from JSON),
+// the value of that field is returned.
+func (r *Response) Lon() float64 {
+	if r == nil {
+		return 0
+	}
+	return fullPrecision(r.lon, r.Longitude)
+}
+
+// 'LatLon' returns both latitude and longitude (see 'Lat' and 'Lon').
+// Use 'Has' method to check whether they are in response.
+func (r *Response) LatLon() (lat, lon float64) {
+	return r.Lat(), r.Lon()
+}
+
+// 'fullPrecision' returns 'full' if 'short' is the same value
+// with less precision, or 'short' otherwise.
+func fullPrecision(full float64, short float32) float64 {
+	if float32(full) == short {
+		return full
+	}
+	return float64(short)
+}
+
+// 'Has' reports whether 'f' field is in JSON response the current object
+// has been decoded from, and its value isn't null. Nested fields are
+// passed as dotted paths ('FieldLocationCapital', 'FieldConnectionAsn').
+// The field of array items ('FieldLocationLanguagesCode') is in response
+// if at least one item has it.
+//
+// Thus you can distinguish real zero values (latitude 0, is_eu false)
+// from fields that haven't been requested or aren't available
+// on your plan.
+//
+// NOTE! It returns false for objects that haven't been decoded from JSON
+// and for unknown fields (use 'Extra' for them).
+func (r *Response) Has(f Field) bool {
+	if r == nil {
+		return false
+	}
+	i, ok := fieldIndex[f]
+	return ok && r.present&fieldTree[i].bit != 0
+}
+
+// 'presentFields' returns the bits of known fields that are in 'obj'
+// JSON object (which is the value of 'prefix' field, "" for the root)
+// and aren't null. Nested objects and arrays of objects are walked
+// recursively using 'codec'.
+func presentFields(codec Codec, obj map[string]json.RawMessage, prefix string) uint64 {
+	var bits uint64
+	for k, v := range obj {
+		if isJSONNull(v) {
+			continue
+		}
+		i, ok := fieldIndex[Field(prefix+k)]
+		if !ok {
+			continue
+		}
+		bits |= fieldTree[i].bit
+		if len(fieldTree[i].children) == 0 {
+			continue
+		}
+		sub := prefix + k + "."
+		switch {
+		case isJSONKind(v, '{'):
+			var nested map[string]json.RawMessage
+			if codec.Unmarshal(v, &nested) == nil {
+				bits |= presentFields(codec, nested, sub)
+			}
+		case isJSONKind(v, '['):
+			var items []map[string]json.RawMessage
+			if codec.Unmarshal(v, &items) == nil {
+				for _, item := range items {
+					bits |= presentFields(codec, item, sub)
+				}
+			}
+		}
+	}
+	return bits
+}
+
+// 'MarshalJSON' implements the 'json.Marshaler' interface
+// for 'Response' class.
+//
+// If the current object has been decoded from JSON ('Raw' isn't empty),
+// the original JSON object is encoded, but with the changed values
+// of declared fields and with 'Extra' fields. Thus, the round-trip
+// is lossless: the fields ipstack returns but 'Response' doesn't declare
+// (even in nested objects) are kept, the values are kept as they are
+// (w/o converting them to golang types and back), and the fields
+// ipstack didn't return aren't added (unless you change them).
+//
+// Otherwise, declared fields and 'Extra' fields are encoded.
+//
+// The zone of requested IP address (see 'Addr' method) is encoded
+// as "ip_zone" field, so it's kept after the round-trip too.
+//
+// NOTE! It has the value receiver, so both 'Response' objects and pointers
+// to them are encoded the same way.
+func (r Response) MarshalJSON() ([]byte, error) {
+	// Coordinates are encoded with full precision unless they're changed
+	fields := struct {
+		*tResponseFields
+		Latitude  interface{} `json:"latitude"`
+		Longitude interface{} `json:"longitude"`
+	}{
+		tResponseFields: (*tResponseFields)(&r),
+		Latitude:        coordinate(r.lat, r.Latitide),
+		Longitude:       coordinate(r.lon, r.Longitude),
+	}
+	cur, err := json.Marshal(&fields)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := decodeJSONTree(cur)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.Raw) > 0 {
+		raw, err := decodeJSONTree(r.Raw)
+		if err != nil {
+			return nil, err
+		}
+		obj = spliceJSON(raw, obj)
+	}
+	m, ok := obj.(map[string]interface{})
+	if !ok {
+		m = make(map[string]interface{})
+	}
+	// Undeclared fields are taken only from 'Extra'
+	for k := range m {
+		if !responseKeys[k] {
+			delete(m, k)
+		}
+	}
+	for k, v := range r.Extra {
+		if !responseKeys[k] && k != cZoneKey {
+			m[k] = v
+		}
+	}
+	if r.zone != "" {
+		m[cZoneKey] = r.zone
+	}
+	return json.Marshal(m)
+}
+
+// 'coordinate' returns 'full' if 'short' is the same value
+// with less precision, or 'short' otherwise (see 'fullPrecision').
+// Unlike 'fullPrecision', 'short' is returned as 'float32',
+// so it's encoded w/o the noise of conversion to 'float64'.
+func coordinate(full float64, short float32) interface{} {
+	if full != 0 && float32(full) == short {
+		return full
+	}
+	return short
+}
+
+// 'decodeJSONTree' decodes 'data' to the tree of maps, slices
+// and scalar values. Numbers are decoded as 'json.Number',
+// so they are kept as they are.
+func decodeJSONTree(data []byte) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// 'spliceJSON' splices 'cur' JSON tree over the 'base' one.
+//
+// If 'cur' is the same as 'base', 'base' is returned as is.
+// Objects (and arrays of the same length) are spliced recursively,
+// so the fields of 'base' that 'cur' doesn't have are kept,
+// and the fields of 'cur' that 'base' doesn't have are added
+// only if they have non-zero values (they were changed).
+// Otherwise 'cur' is returned.
+func spliceJSON(base, cur interface{}) interface{} {
+	if equalJSON(base, cur) {
+		return base
+	}
+	switch c := cur.(type) {
+	case map[string]interface{}:
+		b, ok := base.(map[string]interface{})
+		if !ok {
+			return cur
+		}
+		for k, cv := range c {
+			if bv, ok := b[k]; ok {
+				b[k] = spliceJSON(bv, cv)
+			} else if !isZeroJSON(cv) {
+				b[k] = cv
+			}
+		}
+		return b
+	case []interface{}:
+		b, ok := base.([]interface{})
+		if !ok || len(b) != len(c) {
+			return cur
+		}
+		for i := range b {
+			b[i] = spliceJSON(b[i], c[i])
+		}
+		return b
+	}
+	return cur
+}
+
+// 'equalJSON' reports whether 'a' and 'b' JSON trees are equal.
+// Numbers are equal if they have the same value ("1.0" and "1").
+func equalJSON(a, b interface{}) bool {
+	switch a := a.(type) {
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, av := range a {
+			if bv, ok := b[k]; !ok || !equalJSON(av, bv) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equalJSON(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		if a == b {
+			return true
+		}
+		af, errA := a.Float64()
+		bf, errB := b.Float64()
+		return errA == nil && errB == nil && af == bf
+	}
+	return a == b
+}
+
+// 'isZeroJSON' reports whether 'v' JSON tree is the encoded zero value
+// of golang type: null, false, 0, "", or an object (array) of them.
+func isZeroJSON(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case string:
+		return v == ""
+	case json.Number:
+		f, err := v.Float64()
+		return err == nil && f == 0
+	case map[string]interface{}:
+		for _, item := range v {
+			if !isZeroJSON(item) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// 'isJSONKind' reports whether 'v' JSON value starts with 'c' char.
+func isJSONKind(v json.RawMessage, c byte) bool {
+	v = bytes.TrimSpace(v)
+	return len(v) > 0 && v[0] == c
+}
@@ -0,0 +1,164 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// 'roundTripJSON' is the JSON response with the fields 'Response'
+// doesn't declare, both top-level and nested ones.
+const roundTripJSON = `{
+	"ip": "134.201.250.155",
+	"type": "ipv4",
+	"city": "Los Angeles",
+	"latitude": 34.04530000001,
+	"longitude": -118.24,
+	"region_code": "CA",
+	"location": {
+		"capital": "Washington D.C.",
+		"is_eu": false,
+		"population": 331000000,
+		"languages": [{"code": "en", "name": "English", "native": "English", "script": "Latn"}]
+	},
+	"asn_details": {"asn": 20001}
+}`
+
+// 'marshalTree' encodes 'r' and decodes it back as a JSON tree.
+func marshalTree(t *testing.T, r *Response) map[string]interface{} {
+	t.Helper()
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	tree, err := decodeJSONTree(b)
+	if err != nil {
+		t.Fatalf("decode %s: %v", b, err)
+	}
+	return tree.(map[string]interface{})
+}
+
+func decodeRoundTrip(t *testing.T) *Response {
+	t.Helper()
+	var r Response
+	if err := json.Unmarshal([]byte(roundTripJSON), &r); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	return &r
+}
+
+func TestResponseRoundTripUnchanged(t *testing.T) {
+	r := decodeRoundTrip(t)
+	got := marshalTree(t, r)
+	want, _ := decodeJSONTree([]byte(roundTripJSON))
+	if !equalJSON(got, want) {
+		t.Fatalf("round-trip: got %v, expected %v", got, want)
+	}
+	// Unchanged values are kept as they are
+	if got["latitude"] != json.Number("34.04530000001") {
+		t.Fatalf("latitude: got %v", got["latitude"])
+	}
+	// Fields ipstack didn't return aren't added
+	if _, ok := got["zip"]; ok {
+		t.Fatalf("zip: unexpected %v", got["zip"])
+	}
+}
+
+func TestResponseRoundTripChanged(t *testing.T) {
+	r := decodeRoundTrip(t)
+	r.City = "Santa Monica"
+	r.Zip = "90401"
+	r.Latitide = 34.0195
+	r.Location.Capital = "Sacramento"
+	r.Location.Languages[0].Name = "American English"
+	got := marshalTree(t, r)
+
+	for k, v := range map[string]interface{}{
+		"city":      "Santa Monica",
+		"zip":       "90401",
+		"latitude":  json.Number("34.0195"),
+		"longitude": json.Number("-118.24"),
+		"type":      "ipv4",
+	} {
+		if got[k] != v {
+			t.Errorf("%s: got %v, expected %v", k, got[k], v)
+		}
+	}
+	location := got["location"].(map[string]interface{})
+	if location["capital"] != "Sacramento" {
+		t.Errorf("location.capital: got %v", location["capital"])
+	}
+	// Nested unknown fields are kept next to the changed ones
+	if location["population"] != json.Number("331000000") {
+		t.Errorf("location.population: got %v", location["population"])
+	}
+	language := location["languages"].([]interface{})[0].(map[string]interface{})
+	if language["name"] != "American English" || language["script"] != "Latn" {
+		t.Errorf("location.languages: got %v", language)
+	}
+	if _, ok := location["calling_code"]; ok {
+		t.Errorf("location.calling_code: unexpected %v", location["calling_code"])
+	}
+}
+
+func TestResponseRoundTripExtra(t *testing.T) {
+	r := decodeRoundTrip(t)
+	if _, ok := r.Extra["asn_details"]; !ok {
+		t.Fatalf("Extra: got %v", r.Extra)
+	}
+	got := marshalTree(t, r)
+	if _, ok := got["asn_details"]; !ok {
+		t.Fatalf("asn_details: missed in %v", got)
+	}
+
+	// Top-level undeclared fields are taken only from 'Extra'
+	delete(r.Extra, "asn_details")
+	r.Extra["note"] = json.RawMessage(`"vpn"`)
+	r.Extra["city"] = json.RawMessage(`"ignored"`)
+	got = marshalTree(t, r)
+	if _, ok := got["asn_details"]; ok {
+		t.Errorf("asn_details: unexpected %v", got["asn_details"])
+	}
+	if got["note"] != "vpn" {
+		t.Errorf("note: got %v", got["note"])
+	}
+	if got["city"] != "Los Angeles" {
+		t.Errorf("city: got %v", got["city"])
+	}
+}
+
+func TestResponseMarshalWithoutRaw(t *testing.T) {
+	r := &Response{IP: "1.1.1.1", Latitide: 34.0453}
+	got := marshalTree(t, r)
+	// All declared fields are encoded
+	for k := range responseKeys {
+		if _, ok := got[k]; !ok {
+			t.Errorf("%s: missed", k)
+		}
+	}
+	if got["latitude"] != json.Number("34.0453") {
+		t.Errorf("latitude: got %v", got["latitude"])
+	}
+}
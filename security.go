@@ -0,0 +1,140 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// 'ThreatLevel' is the type of 'ThreatLevel' field of 'Security' class.
+// It's a string, so the values that aren't described by the consts below
+// are kept as is.
+type ThreatLevel string
+
+// Predefined consts each of that represents some documented threat level.
+const (
+	ThreatLevelLow    ThreatLevel = "low"
+	ThreatLevelMedium ThreatLevel = "medium"
+	ThreatLevelHigh   ThreatLevel = "high"
+)
+
+// 'ProxyType' is the type of 'ProxyType' field of 'Security' class.
+// It's a string, so the values that aren't described by the consts below
+// are kept as is.
+type ProxyType string
+
+// Predefined consts each of that represents some documented proxy type.
+const (
+	// Common Gateway Interface proxy
+	ProxyCGI ProxyType = "cgi"
+	// Web proxy
+	ProxyWeb ProxyType = "web"
+	// VPN proxy
+	ProxyVPN ProxyType = "vpn"
+)
+
+// 'CrawlerType' is the type of 'CrawlerType' field of 'Security' class.
+// It's a string, so the values that aren't described by the consts below
+// are kept as is.
+type CrawlerType string
+
+// Predefined consts each of that represents some documented crawler type.
+const (
+	CrawlerUnrecognized         CrawlerType = "unrecognized"
+	CrawlerSearchEngineBot      CrawlerType = "search_engine_bot"
+	CrawlerSiteMonitor          CrawlerType = "site_monitor"
+	CrawlerScreenshotCreator    CrawlerType = "screenshot_creator"
+	CrawlerLinkChecker          CrawlerType = "link_checker"
+	CrawlerWearableComputer     CrawlerType = "wearable_computer"
+	CrawlerWebScraper           CrawlerType = "web_scraper"
+	CrawlerVulnerabilityScanner CrawlerType = "vulnerability_scanner"
+	CrawlerVirusScanner         CrawlerType = "virus_scanner"
+	CrawlerSpeedTester          CrawlerType = "speed_tester"
+	CrawlerFeedFetcher          CrawlerType = "feed_fetcher"
+	CrawlerTool                 CrawlerType = "tool"
+	CrawlerMarketing            CrawlerType = "marketing"
+)
+
+// 'ThreatType' is the type of item of 'ThreatTypes' field of 'Security'
+// class. It's a string, so the values that aren't described by the consts
+// below are kept as is.
+type ThreatType string
+
+// Predefined consts each of that represents some documented threat type.
+const (
+	ThreatTOR              ThreatType = "tor"
+	ThreatFakeCrawler      ThreatType = "fake_crawler"
+	ThreatWebScraper       ThreatType = "web_scraper"
+	ThreatAttackSource     ThreatType = "attack_source"
+	ThreatAttackSourceHTTP ThreatType = "attack_source_http"
+	ThreatAttackSourceMail ThreatType = "attack_source_mail"
+	ThreatAttackSourceSSH  ThreatType = "attack_source_ssh"
+)
+
+// 'ThreatTypes' is the type of 'ThreatTypes' field of 'Security' class.
+// It's the list of threat types the IP is associated with.
+//
+// Web API encodes threat types as an array of strings (or null if there
+// are no threats). Unknown threat types are kept as is.
+type ThreatTypes []ThreatType
+
+// 'Has' reports whether the current list contains 'typ' threat type.
+func (tt ThreatTypes) Has(typ ThreatType) bool {
+	for _, t := range tt {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// 'tSecurityFields' is the internal private type that has the same fields
+// as 'Security' but has no methods. It used to decode 'Security'
+// w/o recursion.
+type tSecurityFields Security
+
+// 'decodeJSON' implements the 'tCodecDecoder' interface for 'Security'
+// class. It decodes 'data' JSON object using 'codec' (threat types too).
+func (s *Security) decodeJSON(codec Codec, data []byte) error {
+	return codec.Unmarshal(data, (*tSecurityFields)(s))
+}
+
+// 'UnmarshalXML' implements the 'xml.Unmarshaler' interface
+// for 'ThreatTypes' class. Threat types are encoded as the text
+// with comma separated types.
+func (tt *ThreatTypes) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var text string
+	if err := d.DecodeElement(&text, &start); err != nil {
+		return err
+	}
+	var list ThreatTypes
+	for _, typ := range strings.Split(text, ",") {
+		if typ = strings.TrimSpace(typ); typ != "" {
+			list = append(list, ThreatType(typ))
+		}
+	}
+	*tt = list
+	return nil
+}
@@ -0,0 +1,73 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestThreatTypesJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  ThreatTypes
+	}{
+		{"Array", `["tor", "attack_source_ssh"]`, ThreatTypes{ThreatTOR, ThreatAttackSourceSSH}},
+		{"Unknown", `["tor", "botnet"]`, ThreatTypes{ThreatTOR, "botnet"}},
+		{"Empty", `[]`, ThreatTypes{}},
+		{"Null", `null`, nil},
+		{"Missed", ``, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := `{"is_tor": true, "threat_level": "high"}`
+			if tt.value != "" {
+				data = `{"is_tor": true, "threat_level": "high", "threat_types": ` + tt.value + `}`
+			}
+			var res Response
+			if err := json.Unmarshal([]byte(`{"security": `+data+`}`), &res); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			sec := res.Security
+			if sec == nil || !sec.IsTOR || sec.ThreatLevel != ThreatLevelHigh {
+				t.Fatalf("Unmarshal: got %+v", sec)
+			}
+			if !reflect.DeepEqual(sec.ThreatTypes, tt.want) {
+				t.Fatalf("ThreatTypes: got %#v, expected %#v", sec.ThreatTypes, tt.want)
+			}
+		})
+	}
+}
+
+func TestThreatTypesHas(t *testing.T) {
+	tt := ThreatTypes{ThreatWebScraper, "botnet"}
+	if !tt.Has(ThreatWebScraper) || !tt.Has("botnet") || tt.Has(ThreatTOR) {
+		t.Fatalf("Has: wrong result for %v", tt)
+	}
+	if ThreatTypes(nil).Has(ThreatTOR) {
+		t.Fatal("Has: nil list has threat type")
+	}
+}
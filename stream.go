@@ -0,0 +1,485 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Default values of streaming lookups behaviour.
+const (
+	// How much requests can be performed at the same time
+	cStreamDefaultWorkers int = 4
+	// How much IP addresses will be sent by one request
+	cStreamDefaultBatchSize int = 1
+	// ipstack limitation of IP addresses in one bulk request
+	cStreamMaxBatchSize int = 50
+)
+
+// 'StreamResult' represents the result of looking up one IP address
+// that has been read from the source passed to 'Stream' or 'StreamFunc'.
+//
+// 'Index' is the zero-based position of IP address in the source,
+// and 'IP' is IP address exactly as it has been read (w/o spaces).
+// It guarantees, that if 'Error' is nil, 'Response' isn't and vice versa.
+//
+// NOTE! If the source can not be read, the last 'StreamResult' object
+// (of the channel returned by 'Stream') will have 'Index' == -1 and
+// contain only reading error.
+type StreamResult struct {
+	Index    int
+	IP       string
+	Response *Response
+	Error    error
+}
+
+// 'tStreamParam' is the internal auxiliary type that is alias to the
+// function only one argument type of 'tStreamConfig' by pointer is receive.
+// It used to represent some parameters for 'Stream' and 'StreamFunc' methods
+// of 'Client' and 'Request' classes, the same way as 'tClientParam' do it
+// for 'Client' constructor.
+type tStreamParam func(cfg *tStreamConfig)
+
+// 'tStreamConfig' is the internal private type that represents
+// the behaviour of one streaming lookup.
+type tStreamConfig struct {
+	workers   int
+	batchSize int
+	ordered   bool
+	skip      func(index int) bool
+	drain     bool
+	limiter   *AdaptiveLimiter
+}
+
+// 'tStreamChunk' is the internal private type that represents the part
+// of the source that will be looked up by one Web API request.
+//
+// 'seq' is the sequence number of chunk and used to restore the input order.
+// 'err' is the error of the whole request (if it is) and it's also stored
+// as 'Error' field of each looked up item.
+type tStreamChunk struct {
+	seq   int
+	items []StreamResult
+	err   error
+}
+
+// 'StreamWorkers' creates a parameter for streaming lookups that specifies
+// how much Web API requests can be performed at the same time.
+// Values less than 1 are treated as 1.
+func StreamWorkers(n int) tStreamParam {
+	return func(cfg *tStreamConfig) {
+		if n < 1 {
+			n = 1
+		}
+		cfg.workers = n
+	}
+}
+
+// 'StreamBatchSize' creates a parameter for streaming lookups that specifies
+// how much IP addresses will be sent by one Web API request.
+// By default each IP address is looked up by its own request.
+//
+// WARNING! Values greater than 1 means bulk queries, and bulk queries are
+// available only on non-free tariff plans. Values greater than 50 (ipstack
+// limitation) are treated as 50.
+func StreamBatchSize(n int) tStreamParam {
+	return func(cfg *tStreamConfig) {
+		switch {
+		case n < 1:
+			n = 1
+		case n > cStreamMaxBatchSize:
+			n = cStreamMaxBatchSize
+		}
+		cfg.batchSize = n
+	}
+}
+
+// 'StreamOrdered' creates a parameter for streaming lookups that specifies
+// the order results will be delivered in.
+// If 'is' is true, results are delivered in the input order.
+// If 'is' is false (by default), results are delivered as they are completed.
+//
+// NOTE! Even in the input order mode memory is bounded: reading of the source
+// is paused while too much results wait for the slow one.
+func StreamOrdered(is bool) tStreamParam {
+	return func(cfg *tStreamConfig) {
+		cfg.ordered = is
+	}
+}
+
+// 'StreamAdaptive' creates a parameter for streaming lookups that delegates
+// the decision of how much Web API requests can be performed at the same
+// time to the 'l' (see 'AdaptiveLimiter' docs).
+// 'StreamWorkers' parameter is ignored if this parameter is passed.
+func StreamAdaptive(l *AdaptiveLimiter) tStreamParam {
+	return func(cfg *tStreamConfig) {
+		cfg.limiter = l
+	}
+}
+
+// 'Stream' is the same as 'Stream' of 'Request' object that is got
+// by 'R' method. See 'Request.Stream' docs for details.
+func (c *Client) Stream(ctx context.Context, src io.Reader, params ...tStreamParam) <-chan StreamResult {
+	return c.R().Stream(ctx, src, params...)
+}
+
+// 'StreamFunc' is the same as 'StreamFunc' of 'Request' object that is got
+// by 'R' method. See 'Request.StreamFunc' docs for details.
+func (c *Client) StreamFunc(ctx context.Context, src io.Reader, fn func(StreamResult) error, params ...tStreamParam) error {
+	return c.R().StreamFunc(ctx, src, fn, params...)
+}
+
+// 'Stream' reads IP addresses from 'src' (one per line or comma separated),
+// looks up them and sends results to the returned channel.
+// The channel is closed when the whole source is read and looked up,
+// or when 'ctx' is done.
+//
+// It's the same as 'StreamFunc', but results are delivered through channel
+// instead of callback. You must read the channel until it's closed
+// or cancel 'ctx'.
+//
+// For example:
+// for res := range c.R().Fields(ipstack.FieldCountryCode).Stream(ctx, f) { ... }
+func (r *Request) Stream(ctx context.Context, src io.Reader, params ...tStreamParam) <-chan StreamResult {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	out := make(chan StreamResult)
+	go func() {
+		defer close(out)
+		err := r.StreamFunc(ctx, src, func(res StreamResult) error {
+			select {
+			case out <- res:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}, params...)
+		if err != nil && err != ctx.Err() {
+			select {
+			case out <- StreamResult{Index: -1, Error: err}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return out
+}
+
+// 'StreamFunc' reads IP addresses from 'src' (one per line or comma separated),
+// looks up them using the current 'Request' object and calls 'fn'
+// for each result. 'fn' is never called concurrently.
+//
+// The source is never loaded to the memory as a whole: only a few chunks
+// (depends by 'StreamWorkers' and 'StreamBatchSize' parameters) are
+// in the memory at the same time.
+// Invalid IP addresses are not sent to the Web API, but reported
+// through 'fn' with the error as well as any other result.
+//
+// If 'fn' returns an error, streaming will be stopped and that error
+// will be returned. Otherwise the error of reading 'src' or
+// the error of 'ctx' (if it's done) is returned.
+func (r *Request) StreamFunc(ctx context.Context, src io.Reader, fn func(StreamResult) error, params ...tStreamParam) error {
+	if err := r.validate(); err != nil {
+		return &OpError{Op: OpStream, Err: err}
+	}
+	if src == nil {
+		return &OpError{Op: OpStream, Err: ErrNilSource}
+	}
+	if fn == nil {
+		return &OpError{Op: OpStream, Err: ErrNilCallback}
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cfg := tStreamConfig{
+		workers:   cStreamDefaultWorkers,
+		batchSize: cStreamDefaultBatchSize,
+	}
+	for _, param := range params {
+		if param != nil {
+			param(&cfg)
+		}
+	}
+	err := r.stream(ctx, src, cfg, func(c *tStreamChunk) error {
+		for _, item := range c.items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		err = ctx.Err()
+	}
+	return err
+}
+
+// 'stream' is the internal private auxiliary method and the engine
+// of all streaming lookups.
+//
+// It reads 'src' by chunks of 'cfg.batchSize' IP addresses, looks up each
+// chunk in one of 'cfg.workers' goroutines and passes completed chunks
+// to 'emit' (in the input order if 'cfg.ordered' is true).
+// 'emit' is never called concurrently.
+//
+// Only 2 * 'cfg.workers' chunks might be read but not emitted
+// at the same time. It makes memory bounded even in the ordered mode.
+//
+// If 'cfg.limiter' isn't nil, the maximum of its limit is used as
+// the number of workers and each worker acquires the permission to perform
+// request from 'cfg.limiter'.
+//
+// If 'cfg.drain' is true, the first failed chunk stops reading of 'src',
+// but in-flight requests aren't cancelled: they are completed and emitted
+// as usual. Chunks that have been read, but haven't been requested yet,
+// are emitted as failed by 'context.Canceled'.
+//
+// It returns the first error of 'emit' or the error of reading 'src'.
+func (r *Request) stream(ctx context.Context, src io.Reader, cfg tStreamConfig, emit func(c *tStreamChunk) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	readCtx, stopReading := context.WithCancel(ctx)
+	defer stopReading()
+	if cfg.limiter != nil {
+		cfg.workers = cfg.limiter.max
+	}
+	// Each chunk must be looked up with the cancellable context
+	// to stop in-flight requests when 'emit' fails
+	req := r.copy().WithContext(ctx)
+	var (
+		pending = make(chan *tStreamChunk)
+		done    = make(chan *tStreamChunk)
+		slots   = make(chan struct{}, 2*cfg.workers)
+		readErr error
+		wg      sync.WaitGroup
+	)
+	go func() {
+		defer close(pending)
+		readErr = readChunks(readCtx, src, cfg.batchSize, cfg.skip, slots, pending)
+	}()
+	for i := 0; i < cfg.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range pending {
+				if cfg.drain && readCtx.Err() != nil {
+					c.fail(readCtx.Err())
+				} else if cfg.limiter == nil {
+					req.lookupChunk(c)
+				} else if release, err := cfg.limiter.Acquire(ctx); err != nil {
+					c.fail(err)
+				} else {
+					req.lookupChunk(c)
+					release(c.err)
+				}
+				if cfg.drain && c.err != nil {
+					stopReading()
+				}
+				done <- c
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	// Collect completed chunks. In the ordered mode chunks that are completed
+	// before the previous ones are held until their turn.
+	// Once 'emit' fails, just drain the rest w/o emitting.
+	var (
+		err  error
+		next int
+		held = map[int]*tStreamChunk{}
+	)
+	for c := range done {
+		if !cfg.ordered {
+			if err == nil {
+				if err = emit(c); err != nil {
+					cancel()
+				}
+			}
+			<-slots
+			continue
+		}
+		held[c.seq] = c
+		for c, ok := held[next]; ok; c, ok = held[next] {
+			delete(held, next)
+			next++
+			if err == nil {
+				if err = emit(c); err != nil {
+					cancel()
+				}
+			}
+			<-slots
+		}
+	}
+	if err == nil {
+		err = readErr
+	}
+	return err
+}
+
+// 'readChunks' is the internal private auxiliary function for 'stream' method.
+// It reads IP addresses from 'src', groups them to the chunks of 'batchSize'
+// items and sends each chunk to 'out' after a free slot is taken from 'slots'.
+//
+// Invalid IP addresses are placed to the chunks too, but already
+// with an error, so they willn't be sent to the Web API.
+// IP addresses for which 'skip' returns true are read but ignored
+// (their indexes are kept). 'skip' might be nil.
+func readChunks(ctx context.Context, src io.Reader, batchSize int, skip func(index int) bool, slots chan<- struct{}, out chan<- *tStreamChunk) error {
+	sc := bufio.NewScanner(src)
+	sc.Split(scanIPs)
+	var (
+		c     *tStreamChunk
+		seq   int
+		index int
+	)
+	send := func() bool {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return false
+		}
+		select {
+		case out <- c:
+		case <-ctx.Done():
+			return false
+		}
+		c = nil
+		return true
+	}
+	for sc.Scan() {
+		item := StreamResult{Index: index, IP: sc.Text()}
+		if index++; skip != nil && skip(item.Index) {
+			continue
+		}
+		if _, ok := canonicalIP(item.IP); !ok {
+			item.Error = &OpError{Op: OpIP, IPs: []string{item.IP}, Err: ErrInvalidIP}
+		}
+		if c == nil {
+			c = &tStreamChunk{seq: seq, items: make([]StreamResult, 0, batchSize)}
+			seq++
+		}
+		if c.items = append(c.items, item); len(c.items) == batchSize && !send() {
+			return nil
+		}
+	}
+	if c != nil && !send() {
+		return nil
+	}
+	if err := sc.Err(); err != nil {
+		return &OpError{Op: OpStream, Err: fmt.Errorf("Error reading source: %w", err)}
+	}
+	return nil
+}
+
+// 'scanIPs' is the split function for 'bufio.Scanner' that splits
+// the source to the IP addresses separated by commas or new lines.
+// All spaces around IP addresses are trimmed and empty items are skipped.
+func scanIPs(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	start := 0
+	for start < len(data) && isIPSeparator(data[start]) {
+		start++
+	}
+	if i := bytes.IndexAny(data[start:], ",\r\n"); i >= 0 {
+		return start + i + 1, bytes.TrimSpace(data[start : start+i]), nil
+	}
+	if atEOF && start < len(data) {
+		return len(data), bytes.TrimSpace(data[start:]), nil
+	}
+	return start, nil, nil
+}
+
+// 'isIPSeparator' reports whether 'b' can't be the part of IP address
+// in the source of streaming lookup and should be skipped.
+func isIPSeparator(b byte) bool {
+	return b == ',' || b == '\r' || b == '\n' || b == ' ' || b == '\t'
+}
+
+// 'lookupChunk' is the internal private auxiliary method for 'stream' method.
+// It looks up all valid IP addresses of 'c' by one Web API request
+// and stores results to the items of 'c'.
+// If request fails, the error is stored as 'err' field of 'c' and
+// as 'Error' field of each looked up item.
+func (r *Request) lookupChunk(c *tStreamChunk) {
+	ips := make([]string, 0, len(c.items))
+	idx := make([]int, 0, len(c.items))
+	for i := range c.items {
+		if c.items[i].Error == nil {
+			ips = append(ips, c.items[i].IP)
+			idx = append(idx, i)
+		}
+	}
+	if len(ips) == 0 {
+		return
+	}
+	res, err := r.lookup(ips)
+	if err != nil {
+		c.fail(err)
+		return
+	}
+	for n, i := range idx {
+		c.items[i].Response = res[n]
+	}
+}
+
+// 'fail' marks the whole chunk as failed by 'err': it's stored as 'err' field
+// of chunk and as 'Error' field of each item that hasn't an error yet.
+func (c *tStreamChunk) fail(err error) {
+	for i := range c.items {
+		if c.items[i].Error == nil {
+			c.items[i].Error = err
+		}
+	}
+	c.err = err
+}
+
+// 'lookup' performs 'IP' (if only one IP address passed) or 'IPs' request,
+// checks API error and decodes response.
+// It guarantees, that if error is nil, the returned slice has
+// the same length as 'ips' and the same order.
+func (r *Request) lookup(ips []string) ([]*Response, error) {
+	if len(ips) == 1 {
+		res := &Response{}
+		if err := r.IP(ips[0]).decode(res); err != nil {
+			return nil, err
+		}
+		return []*Response{res}, nil
+	}
+	res := make([]*Response, 0, len(ips))
+	if err := r.IPs(ips...).decode(&res); err != nil {
+		return nil, err
+	}
+	if len(res) != len(ips) {
+		err := fmt.Errorf("%w (%d, expected %d)", ErrResponseMismatch, len(res), len(ips))
+		return nil, &OpError{Op: OpIPs, IPs: ips, Err: err}
+	}
+	return res, nil
+}
@@ -0,0 +1,130 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 'streamSource' is the source of streaming lookups tests:
+// 8 valid IP addresses and one invalid at index 4.
+const streamSource = "1.0.0.1\n1.0.0.2, 1.0.0.3\r\n1.0.0.4\nbogus\n1.0.0.6\n1.0.0.7\n1.0.0.8\n1.0.0.9\n"
+
+// 'slowFirst' answers like 'echoJSON', but the less the last octet
+// of the queried IP address is, the longer the answer is delayed,
+// so the first IP addresses are completed the last.
+func slowFirst(req *http.Request) (int, string) {
+	ip := queriedIPs(req)[0]
+	delay := 10 - int(ip[len(ip)-1]-'0')
+	time.Sleep(time.Duration(delay) * 3 * time.Millisecond)
+	return echoJSON(req)
+}
+
+// 'collectStream' reads all results of 'Stream'.
+func collectStream(t *testing.T, c *Client, params ...tStreamParam) []StreamResult {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var results []StreamResult
+	for res := range c.Stream(ctx, strings.NewReader(streamSource), params...) {
+		results = append(results, res)
+	}
+	if ctx.Err() != nil {
+		t.Fatalf("Stream: %v", ctx.Err())
+	}
+	return results
+}
+
+// 'checkStreamResult' checks one result of streaming 'streamSource'.
+func checkStreamResult(t *testing.T, res StreamResult) {
+	t.Helper()
+	if res.Index == 4 {
+		if res.IP != "bogus" || !errors.Is(res.Error, ErrInvalidIP) {
+			t.Fatalf("result %d: got %q, %v, expected invalid IP error", res.Index, res.IP, res.Error)
+		}
+		return
+	}
+	if res.Error != nil {
+		t.Fatalf("result %d: %v", res.Index, res.Error)
+	}
+	if res.Response == nil || res.Response.IP != res.IP {
+		t.Fatalf("result %d: response doesn't match IP %q: %+v", res.Index, res.IP, res.Response)
+	}
+}
+
+func TestStreamOrdered(t *testing.T) {
+	c := newFakeClient(t, tFakeTransport(slowFirst))
+	for _, batch := range []int{1, 3} {
+		results := collectStream(t, c, StreamWorkers(4), StreamBatchSize(batch), StreamOrdered(true))
+		if len(results) != 9 {
+			t.Fatalf("batch %d: got %d results, expected 9", batch, len(results))
+		}
+		for i, res := range results {
+			if res.Index != i {
+				t.Fatalf("batch %d: result %d has index %d", batch, i, res.Index)
+			}
+			checkStreamResult(t, res)
+		}
+	}
+}
+
+func TestStreamUnordered(t *testing.T) {
+	c := newFakeClient(t, tFakeTransport(slowFirst))
+	results := collectStream(t, c, StreamWorkers(8))
+	if len(results) != 9 {
+		t.Fatalf("got %d results, expected 9", len(results))
+	}
+	seen := map[int]bool{}
+	for _, res := range results {
+		if seen[res.Index] {
+			t.Fatalf("index %d is delivered twice", res.Index)
+		}
+		seen[res.Index] = true
+		checkStreamResult(t, res)
+	}
+	// The slowest (the first) IP address must not be the first delivered
+	if results[0].Index == 0 {
+		t.Fatalf("results are delivered in the input order")
+	}
+}
+
+func TestStreamFuncStopsOnCallbackError(t *testing.T) {
+	c := newFakeClient(t, tFakeTransport(echoJSON))
+	stop := errors.New("stop")
+	n := 0
+	err := c.StreamFunc(context.Background(), strings.NewReader(streamSource), func(StreamResult) error {
+		if n++; n == 2 {
+			return stop
+		}
+		return nil
+	}, StreamOrdered(true))
+	if err != stop || n != 2 {
+		t.Fatalf("got %v after %d results, expected stop after 2", err, n)
+	}
+}
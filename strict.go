@@ -0,0 +1,143 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 'ParamsError' is the aggregated error of 'Client' constructor in strict
+// mode (see 'ParamStrict'). It contains all found misconfigurations:
+// arguments of unknown types ('ErrUnknownParam'), options passed more than
+// once with different values ('ErrConflictingParams') and invalid values
+// ('ErrInvalidParam', 'ErrUnknownField', 'ErrUnknownLanguage', etc).
+//
+// It's wrapped by 'OpError' object, so use 'errors.As' to get it,
+// or 'errors.Is' to check whether some of errors is one of 'Err...' errors.
+type ParamsError struct {
+	Errs []error
+}
+
+// 'Error' implements the 'error' interface for 'ParamsError' class.
+// It returns all errors joined by "; ".
+func (e *ParamsError) Error() string {
+	if e == nil {
+		return ""
+	}
+	s := make([]string, len(e.Errs))
+	for i := range e.Errs {
+		s[i] = e.Errs[i].Error()
+	}
+	return fmt.Sprintf("Invalid configuration (%d): %s",
+		len(e.Errs), strings.Join(s, "; "))
+}
+
+// 'Unwrap' returns all errors of the current object.
+// It's used by 'errors.Is' and 'errors.As' (Go 1.20+).
+func (e *ParamsError) Unwrap() []error {
+	if e == nil {
+		return nil
+	}
+	return e.Errs
+}
+
+// 'Is' reports whether some of errors of the current object is 'target'.
+// It's used by 'errors.Is' and you shouldn't call it directly.
+func (e *ParamsError) Is(target error) bool {
+	if e == nil {
+		return false
+	}
+	for _, err := range e.Errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// 'ParamStrict' creates a parameter for 'Client' constructors that enables
+// (or disables if 'is' is false) the strict mode of constructor.
+//
+// In strict mode 'Client' constructor doesn't ignore anything: arguments
+// of unknown types, nil parameters, empty token, options passed more than once
+// with different values and all invalid values are reported at once
+// by the 'ParamsError' object (wrapped by 'OpError').
+//
+// NOTE! It doesn't matter where 'ParamStrict' is placed in arguments,
+// all of them are checked.
+func ParamStrict(is bool) tClientParam {
+	return func(c *Client) {
+		if c != nil {
+			c.strict = is
+		}
+	}
+}
+
+// 'paramError' saves 'err' as the error of the argument that is applying now
+// by the 'Client' constructor. Errors are reported only in strict mode.
+func (c *Client) paramError(err error) {
+	if c.paramPos > 0 {
+		err = fmt.Errorf("argument #%d: %w", c.paramPos, err)
+	}
+	c.paramErrs = append(c.paramErrs, err)
+}
+
+// 'noteParam' saves 'value' of the option 'name' and reports
+// 'ErrConflictingParams' if that option has been already passed
+// with another value.
+//
+// NOTE! Values are not included to the error, because they might be secret.
+// Secret values (the token) must be passed as 'secretDigest' of them,
+// and all values are dropped when 'Client' constructor returns.
+func (c *Client) noteParam(name, value string) {
+	if c.paramsSeen == nil {
+		c.paramsSeen = make(map[string]string)
+	}
+	if prev, ok := c.paramsSeen[name]; ok && prev != value {
+		c.paramError(fmt.Errorf("%w: %s is set more than once with different values",
+			ErrConflictingParams, name))
+		return
+	}
+	c.paramsSeen[name] = value
+}
+
+// 'checkTokenArg' checks the token passed as 'string' or '[]byte' argument.
+func (c *Client) checkTokenArg(token string) {
+	if token = strings.TrimSpace(token); token == "" {
+		c.paramError(fmt.Errorf("%w: empty token", ErrInvalidParam))
+		return
+	}
+	c.noteParam("token", secretDigest(token))
+}
+
+// 'secretDigest' returns the SHA-256 hash of 'secret' (the token),
+// so secret values can be compared by 'noteParam' w/o keeping them.
+func secretDigest(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,150 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestStrictReportsAllErrors(t *testing.T) {
+	_, err := New(
+		ParamStrict(true),
+		"secret-token",
+		ParamToken("other-token"),
+		42,
+		nil,
+		tClientParam(nil),
+		ParamAsyncWorkers(0),
+		ParamFields("bogus"),
+		ParamLanguage("xx"),
+		ParamDisableFirstMeCall(),
+	)
+	var opErr *OpError
+	if !errors.As(err, &opErr) || opErr.Op != OpNew {
+		t.Fatalf("New: got %v, expected OpError of New", err)
+	}
+	var pe *ParamsError
+	if !errors.As(err, &pe) {
+		t.Fatalf("New: got %v, expected ParamsError", err)
+	}
+	if len(pe.Errs) != 7 {
+		t.Errorf("New: got %d errors, expected 7: %v", len(pe.Errs), pe)
+	}
+	for _, target := range []error{
+		ErrConflictingParams, ErrUnknownParam, ErrInvalidParam,
+		ErrUnknownField, ErrUnknownLanguage,
+	} {
+		if !errors.Is(err, target) {
+			t.Errorf("New: %v doesn't wrap %v", err, target)
+		}
+	}
+	if msg := err.Error(); strings.Contains(msg, "secret-token") || strings.Contains(msg, "other-token") {
+		t.Errorf("New: error contains the token: %s", msg)
+	}
+	if !strings.Contains(pe.Errs[0].Error(), "argument #3") {
+		t.Errorf("New: the first error has no position: %v", pe.Errs[0])
+	}
+}
+
+func TestStrictAcceptsValidParams(t *testing.T) {
+	c, err := New(
+		ParamStrict(true),
+		"token",
+		ParamToken(" token "),
+		ParamDisableFirstMeCall(),
+		ParamDisableFirstMeCall(true),
+		ParamFields(FieldCity),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if c.paramsSeen != nil || c.paramErrs != nil {
+		t.Error("New: checked params are kept after constructor")
+	}
+}
+
+func TestNonStrictIgnoresMisconfigurations(t *testing.T) {
+	_, err := New("token", ParamToken(""), 42, nil, ParamAsyncWorkers(0),
+		ParamEnableSecurity(true), ParamEnableSecurity(false), ParamDisableFirstMeCall())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	// Invalid values saved to the request are reported anyway
+	if _, err = New("token", ParamFields("bogus"), ParamDisableFirstMeCall()); !errors.Is(err, ErrUnknownField) {
+		t.Fatalf("New: got %v, expected %v", err, ErrUnknownField)
+	}
+}
+
+func TestParamsAreReversible(t *testing.T) {
+	calls := 0
+	c := newFakeClient(t, tFakeTransport(func(req *http.Request) (int, string) {
+		calls++
+		return echoJSON(req)
+	}),
+		ParamEnableSecurity(true), ParamEnableSecurity(false),
+		ParamHostname(true), ParamHostname(false),
+		ParamUseHTTPS(true), ParamUseHTTPS(false),
+		ParamFields(FieldCity, FieldZip, FieldLocation), ParamWithoutFields(FieldZip, FieldLocation),
+		ParamDisableFirstMeCall(false),
+	)
+	args := c.baseReq.reqArgs
+	if args.Get("security") != "" || args.Get("hostname") != "" {
+		t.Errorf("security and hostname aren't disabled: %s", c.baseReq.reqArgsBuilt)
+	}
+	if c.baseReq.endpoint != cApiEndpointHTTP {
+		t.Errorf("HTTPS isn't disabled: %s", c.baseReq.endpoint)
+	}
+	if args.Get("fields") != string(FieldCity) {
+		t.Errorf("got fields %q, expected %q", args.Get("fields"), FieldCity)
+	}
+	if calls != 1 {
+		t.Errorf("the first Me call isn't enabled back: %d requests", calls)
+	}
+
+	c = newFakeClient(t, tFakeTransport(echoJSON), ParamFields(FieldCity), ParamResetFields())
+	if c.baseReq.reqArgs.Get("fields") != "" || !c.baseReq.fields.IsEmpty() {
+		t.Errorf("fields aren't reset: %s", c.baseReq.reqArgsBuilt)
+	}
+}
+
+func TestParamsDoNotKeepToken(t *testing.T) {
+	c := &Client{baseReq: &Request{}}
+	c.checkTokenArg("secret-token")
+	ParamToken("secret-token")(c)
+	for name, v := range c.paramsSeen {
+		if strings.Contains(v, "secret") {
+			t.Errorf("%s: the token is kept as is", name)
+		}
+	}
+	if len(c.paramErrs) != 0 {
+		t.Errorf("the same token is reported as conflict: %v", c.paramErrs)
+	}
+	ParamToken("another-token")(c)
+	if len(c.paramErrs) != 1 || !errors.Is(c.paramErrs[0], ErrConflictingParams) {
+		t.Errorf("different tokens aren't reported: %v", c.paramErrs)
+	}
+}
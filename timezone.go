@@ -0,0 +1,244 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tuning of searching DST transitions (see 'NextTransition').
+const (
+	// Step of scanning changes of zone offset. Transitions closer
+	// than this step to each other might be skipped.
+	cTransitionScanStep = 12 * time.Hour
+	// How far transitions are searched
+	cTransitionScanLimit = 2 * 366 * 24 * time.Hour
+)
+
+// Layouts of 'current_time' field of 'TimeZone' that are supported.
+// Layouts w/o offset are treated in 'GMTOffset' zone.
+var timeZoneLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+}
+
+// 'locations' is the cache of loaded time zones by their IDs.
+// The value is '*time.Location' or nil if time zone can't be loaded.
+var locations sync.Map
+
+// 'ZoneTransition' represents the change of zone offset
+// (DST start or end, or the change of standard time).
+// See 'NextTransition' and 'Transitions' methods of 'TimeZone' class.
+type ZoneTransition struct {
+	// The first moment of new offset
+	At time.Time
+	// The name of zone abbreviation after transition (e.g. "PDT")
+	Name string
+	// The offset after transition in seconds east of UTC
+	Offset int
+	// Whether daylight saving time is in effect after transition
+	IsDST bool
+}
+
+// 'tTimeZoneFields' is the internal private type that has the same fields
+// as 'TimeZone' but has no methods. It used to decode 'TimeZone'
+// w/o recursion.
+type tTimeZoneFields TimeZone
+
+// 'UnmarshalJSON' implements the 'json.Unmarshaler' interface
+// for 'TimeZone' class.
+//
+// It's tolerant to the format of 'current_time': RFC 3339 with or w/o 'T'
+// separator and colon in offset, w/o offset at all (then 'GMTOffset'
+// is used), unix timestamp, empty string or null. If 'current_time'
+// can't be parsed, 'CurrentTime' is left zero, but the other fields
+// are decoded anyway.
+func (tz *TimeZone) UnmarshalJSON(data []byte) error {
+	return tz.decodeJSON(defaultCodec, data)
+}
+
+// 'decodeJSON' implements the 'tCodecDecoder' interface
+// for 'TimeZone' class. It's the same as 'UnmarshalJSON' but uses 'codec'.
+func (tz *TimeZone) decodeJSON(codec Codec, data []byte) error {
+	aux := struct {
+		*tTimeZoneFields
+		CurrentTime json.RawMessage `json:"current_time"`
+	}{tTimeZoneFields: (*tTimeZoneFields)(tz)}
+	if err := codec.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	tz.CurrentTime = parseTimeZoneTime(codec, aux.CurrentTime, tz.fixedZone())
+	return nil
+}
+
+// 'UnmarshalXML' implements the 'xml.Unmarshaler' interface
+// for 'TimeZone' class. It's tolerant to the format of 'current_time'
+// the same way as 'UnmarshalJSON'.
+func (tz *TimeZone) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	aux := struct {
+		*tTimeZoneFields
+		CurrentTime string `xml:"current_time"`
+	}{tTimeZoneFields: (*tTimeZoneFields)(tz)}
+	if err := d.DecodeElement(&aux, &start); err != nil {
+		return err
+	}
+	tz.CurrentTime = parseTimeZoneString(aux.CurrentTime, tz.fixedZone())
+	return nil
+}
+
+// 'parseTimeZoneTime' parses 'v' JSON value of 'current_time' field
+// (see 'UnmarshalJSON' of 'TimeZone' class) using 'codec'. Times w/o offset
+// are treated in 'loc' zone. If 'v' can't be parsed, zero time is returned.
+func parseTimeZoneTime(codec Codec, v json.RawMessage, loc *time.Location) time.Time {
+	v = bytes.TrimSpace(v)
+	if len(v) == 0 || bytes.Equal(v, []byte("null")) {
+		return time.Time{}
+	}
+	var s string
+	if v[0] != '"' {
+		s = string(v)
+	} else if codec.Unmarshal(v, &s) != nil {
+		return time.Time{}
+	}
+	return parseTimeZoneString(s, loc)
+}
+
+// 'parseTimeZoneString' is the same as 'parseTimeZoneTime'
+// but parses the text of 'current_time' field.
+func parseTimeZoneString(s string, loc *time.Location) time.Time {
+	if s = strings.TrimSpace(s); s == "" {
+		return time.Time{}
+	}
+	for _, layout := range timeZoneLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t
+		}
+	}
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(sec, 0).In(loc)
+	}
+	return time.Time{}
+}
+
+// 'Location' returns the time zone as golang '*time.Location' object.
+// It's loaded by 'ID' (e.g. "America/Los_Angeles") and cached.
+// If it can't be loaded (no 'ID' or no time zone database), the fixed zone
+// with 'GMTOffset' offset and 'Code' name is returned.
+//
+// NOTE! The fixed zone doesn't know about DST, so the times converted
+// to it after DST transition will be wrong by DST offset.
+func (tz *TimeZone) Location() *time.Location {
+	if tz == nil {
+		return time.UTC
+	}
+	if tz.ID != "" {
+		if loc, ok := locations.Load(tz.ID); ok {
+			if loc != nil {
+				return loc.(*time.Location)
+			}
+		} else if loc, err := time.LoadLocation(tz.ID); err == nil {
+			locations.Store(tz.ID, loc)
+			return loc
+		} else {
+			locations.Store(tz.ID, nil)
+		}
+	}
+	return tz.fixedZone()
+}
+
+// 'fixedZone' returns the fixed zone with 'GMTOffset' offset
+// and 'Code' name.
+func (tz *TimeZone) fixedZone() *time.Location {
+	if tz.GMTOffset == 0 && tz.Code == "" {
+		return time.UTC
+	}
+	return time.FixedZone(tz.Code, tz.GMTOffset)
+}
+
+// 'Now' returns the current local time of the time zone.
+// Unlike 'CurrentTime' field, it's the time of calling, not the time
+// of Web API response.
+func (tz *TimeZone) Now() time.Time {
+	return time.Now().In(tz.Location())
+}
+
+// 'In' returns 't' converted to the time zone (see 'Location').
+func (tz *TimeZone) In(t time.Time) time.Time {
+	return t.In(tz.Location())
+}
+
+// 'NextTransition' returns the first change of zone offset (DST start or end)
+// after 't' in the time zone, or false if there is no transition
+// in the next two years (or time zone is the fixed zone).
+func (tz *TimeZone) NextTransition(t time.Time) (ZoneTransition, bool) {
+	loc := tz.Location()
+	_, offset := t.In(loc).Zone()
+	for from := t; from.Sub(t) < cTransitionScanLimit; from = from.Add(cTransitionScanStep) {
+		to := from.Add(cTransitionScanStep)
+		if _, o := to.In(loc).Zone(); o == offset {
+			continue
+		}
+		// Offset has been changed between 'from' and 'to',
+		// find the moment of transition by binary search
+		for to.Sub(from) > time.Second {
+			mid := from.Add(to.Sub(from) / 2)
+			if _, o := mid.In(loc).Zone(); o == offset {
+				from = mid
+			} else {
+				to = mid
+			}
+		}
+		at := to.Truncate(time.Second).In(loc)
+		if _, o := at.Zone(); o == offset {
+			at = at.Add(time.Second)
+		}
+		name, o := at.Zone()
+		return ZoneTransition{At: at, Name: name, Offset: o, IsDST: at.IsDST()}, true
+	}
+	return ZoneTransition{}, false
+}
+
+// 'Transitions' returns all changes of zone offset (DST starts and ends)
+// in ['from', 'to') range in the time zone.
+func (tz *TimeZone) Transitions(from, to time.Time) []ZoneTransition {
+	var list []ZoneTransition
+	for {
+		tr, ok := tz.NextTransition(from)
+		if !ok || !tr.At.Before(to) {
+			return list
+		}
+		list = append(list, tr)
+		from = tr.At
+	}
+}
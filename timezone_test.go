@@ -0,0 +1,172 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestTimeZoneCurrentTime(t *testing.T) {
+	pst := time.FixedZone("PST", -8*3600)
+	tests := []struct {
+		name, value string
+		want        time.Time
+	}{
+		{"RFC3339", `"2025-03-08T17:30:00-08:00"`, time.Date(2025, 3, 8, 17, 30, 0, 0, pst)},
+		{"RFC3339Nano", `"2025-03-08T17:30:00.5-08:00"`, time.Date(2025, 3, 8, 17, 30, 0, 5e8, pst)},
+		{"NoColon", `"2025-03-08T17:30:00-0800"`, time.Date(2025, 3, 8, 17, 30, 0, 0, pst)},
+		{"Space", `"2025-03-08 17:30:00-08:00"`, time.Date(2025, 3, 8, 17, 30, 0, 0, pst)},
+		{"SpaceNoColon", `"2025-03-08 17:30:00-0800"`, time.Date(2025, 3, 8, 17, 30, 0, 0, pst)},
+		{"NoOffset", `"2025-03-08T17:30:00"`, time.Date(2025, 3, 8, 17, 30, 0, 0, pst)},
+		{"SpaceNoOffset", `"2025-03-08 17:30:00"`, time.Date(2025, 3, 8, 17, 30, 0, 0, pst)},
+		{"UnixString", `"1741483800"`, time.Unix(1741483800, 0)},
+		{"UnixNumber", `1741483800`, time.Unix(1741483800, 0)},
+		{"Null", `null`, time.Time{}},
+		{"Empty", `""`, time.Time{}},
+		{"Garbage", `"yesterday"`, time.Time{}},
+		{"Object", `{"t": 1}`, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := `{"id": "America/Los_Angeles", "current_time": ` + tt.value +
+				`, "gmt_offset": -28800, "code": "PST", "is_daylight_saving": false}`
+			var tz TimeZone
+			if err := json.Unmarshal([]byte(data), &tz); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if !tz.CurrentTime.Equal(tt.want) || tz.CurrentTime.IsZero() != tt.want.IsZero() {
+				t.Fatalf("CurrentTime: got %v, expected %v", tz.CurrentTime, tt.want)
+			}
+			// The other fields are decoded anyway
+			if tz.ID != "America/Los_Angeles" || tz.GMTOffset != -28800 || tz.Code != "PST" {
+				t.Fatalf("TimeZone: got %+v", tz)
+			}
+			if tt.want.IsZero() {
+				return
+			}
+			if _, offset := tz.CurrentTime.Zone(); offset != -28800 {
+				t.Fatalf("CurrentTime: got offset %d", offset)
+			}
+		})
+	}
+}
+
+func TestTimeZoneLocation(t *testing.T) {
+	tests := []struct {
+		name   string
+		tz     *TimeZone
+		want   string
+		offset int
+	}{
+		{"Nil", nil, "UTC", 0},
+		{"ID", &TimeZone{ID: "America/Los_Angeles", GMTOffset: -28800, Code: "PST"}, "America/Los_Angeles", -28800},
+		{"UnknownID", &TimeZone{ID: "Mars/Olympus_Mons", GMTOffset: 3600, Code: "OMT"}, "OMT", 3600},
+		{"NoID", &TimeZone{GMTOffset: -7200, Code: "XST"}, "XST", -7200},
+		{"Empty", &TimeZone{}, "UTC", 0},
+	}
+	winter := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := tt.tz.Location()
+			if loc.String() != tt.want {
+				t.Fatalf("Location: got %q, expected %q", loc, tt.want)
+			}
+			if _, offset := winter.In(loc).Zone(); offset != tt.offset {
+				t.Fatalf("offset: got %d, expected %d", offset, tt.offset)
+			}
+		})
+	}
+}
+
+func TestTimeZoneNextTransition(t *testing.T) {
+	tz := &TimeZone{ID: "America/Los_Angeles", GMTOffset: -28800, Code: "PST"}
+	tests := []struct {
+		name string
+		from time.Time
+		want ZoneTransition
+	}{
+		{"DSTStart", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			ZoneTransition{At: time.Date(2025, 3, 9, 10, 0, 0, 0, time.UTC), Name: "PDT", Offset: -25200, IsDST: true}},
+		{"RightBefore", time.Date(2025, 3, 9, 9, 59, 59, 0, time.UTC),
+			ZoneTransition{At: time.Date(2025, 3, 9, 10, 0, 0, 0, time.UTC), Name: "PDT", Offset: -25200, IsDST: true}},
+		{"DSTEnd", time.Date(2025, 3, 9, 10, 0, 0, 0, time.UTC),
+			ZoneTransition{At: time.Date(2025, 11, 2, 9, 0, 0, 0, time.UTC), Name: "PST", Offset: -28800}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr, ok := tz.NextTransition(tt.from)
+			if !ok {
+				t.Fatalf("NextTransition: not found")
+			}
+			if !tr.At.Equal(tt.want.At) || tr.Name != tt.want.Name ||
+				tr.Offset != tt.want.Offset || tr.IsDST != tt.want.IsDST {
+				t.Fatalf("NextTransition: got %+v, expected %+v", tr, tt.want)
+			}
+			if tr.At.Location().String() != tz.ID {
+				t.Fatalf("NextTransition: got location %v", tr.At.Location())
+			}
+		})
+	}
+}
+
+func TestTimeZoneNoTransition(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tz := range []*TimeZone{
+		{ID: "Asia/Tokyo", GMTOffset: 32400, Code: "JST"},
+		{ID: "Mars/Olympus_Mons", GMTOffset: 3600, Code: "OMT"},
+	} {
+		if tr, ok := tz.NextTransition(from); ok {
+			t.Fatalf("%s: unexpected transition %+v", tz.ID, tr)
+		}
+	}
+}
+
+func TestTimeZoneTransitions(t *testing.T) {
+	tz := &TimeZone{ID: "America/Los_Angeles"}
+	list := tz.Transitions(
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+	)
+	want := []time.Time{
+		time.Date(2025, 3, 9, 10, 0, 0, 0, time.UTC),
+		time.Date(2025, 11, 2, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 8, 10, 0, 0, 0, time.UTC),
+		time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC),
+	}
+	if len(list) != len(want) {
+		t.Fatalf("Transitions: got %v", list)
+	}
+	for i, tr := range list {
+		if !tr.At.Equal(want[i]) || tr.IsDST != (i%2 == 0) {
+			t.Fatalf("Transitions[%d]: got %+v, expected %v", i, tr, want[i])
+		}
+	}
+	// The end of range is exclusive
+	if list := tz.Transitions(want[0].Add(-time.Hour), want[0]); len(list) != 0 {
+		t.Fatalf("Transitions: got %v", list)
+	}
+}
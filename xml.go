@@ -0,0 +1,209 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// 'Format' is the format of Web API response.
+// Use predefined consts started with 'Format...' prefix.
+type Format string
+
+// Predefined consts each of that represents some format
+// of Web API response supported by ipstack.
+const (
+	FormatJSON Format = "json"
+	FormatXML  Format = "xml"
+)
+
+// 'Format' specifies the format of Web API response: 'FormatJSON'
+// (by default) or 'FormatXML'. 'CheckError' and 'DecodeTo' methods
+// of 'RawResponse' detect errors and decode the response in that format.
+//
+// WARNING! Unsupported formats are rejected. The error (wraps
+// 'ErrUnknownFormat') is saved to the current object and will be returned
+// by each request ('IP', 'IPs', 'Me') w/o performing it.
+//
+// NOTE! 'Raw', 'Extra' fields and 'Has' method of 'Response' work only
+// with JSON responses.
+func (r *Request) Format(format Format) *Request {
+	if r == nil {
+		return nil
+	}
+	format = Format(strings.ToLower(strings.TrimSpace(string(format))))
+	if format != FormatJSON && format != FormatXML {
+		if r.err == nil {
+			r.err = fmt.Errorf("%w: %q", ErrUnknownFormat, string(format))
+		}
+		return r
+	}
+	r.format = format
+	return r.setArg("output", string(format))
+}
+
+// 'ParamFormat' creates a parameter for 'Client' constructors that
+// specifies the format of Web API response (see 'Format' method of 'Request').
+//
+// WARNING! Unsupported formats are rejected and 'Client' constructor returns
+// an error (wraps 'ErrUnknownFormat').
+func ParamFormat(format Format) tClientParam {
+	return func(c *Client) {
+		if c != nil {
+			c.noteParam("format", string(format))
+			c.baseReq = c.baseReq.Format(format)
+		}
+	}
+}
+
+// 'isXML' reports whether the current object contains XML response.
+// If the format is unknown (object hasn't been got from 'Request'),
+// it's detected by the first char of response.
+func (r *RawResponse) isXML() bool {
+	if r.format != "" {
+		return r.format == FormatXML
+	}
+	trimmed := bytes.TrimSpace(r.RawData)
+	return len(trimmed) > 0 && trimmed[0] == '<'
+}
+
+// 'tXMLResponseError' is the same as 'tResponseError' but for XML response.
+//
+// Web API error XML message looks like:
+// "<result><success>false</success><error><code>N</code>...</error></result>".
+type tXMLResponseError struct {
+	Success string  `xml:"success"`
+	Error   *APIErr `xml:"error"`
+}
+
+// 'checkXMLError' is the same as 'CheckError' but for XML response.
+// It returns Web API error (or nil) and the error of decoding.
+func checkXMLError(data []byte) (*APIErr, error) {
+	var errApi tXMLResponseError
+	if err := xml.Unmarshal(data, &errApi); err != nil {
+		return nil, err
+	}
+	if errApi.Error == nil {
+		return nil, nil
+	}
+	if success, err := strconv.ParseBool(strings.TrimSpace(errApi.Success)); err == nil && success {
+		return nil, nil
+	}
+	return errApi.Error, nil
+}
+
+// 'decodeXML' decodes 'data' XML response to 'dst'.
+// If 'dst' is the pointer to slice, each child element of the root element
+// is decoded as item of that slice (response of bulk request).
+func decodeXML(data []byte, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return xml.Unmarshal(data, dst)
+	}
+	wrapper := reflect.New(reflect.StructOf([]reflect.StructField{{
+		Name: "Items",
+		Type: v.Elem().Type(),
+		Tag:  `xml:",any"`,
+	}}))
+	if err := xml.Unmarshal(data, wrapper.Interface()); err != nil {
+		return err
+	}
+	v.Elem().Set(wrapper.Elem().Field(0))
+	return nil
+}
+
+// 'xmlItems' splits 'data' XML response of bulk request to the items:
+// child elements of the root element. If the root element is the response
+// about one IP address (it has 'ip' child element), it's the only item.
+// Returns false if 'data' can't be parsed.
+func xmlItems(data []byte) ([][]byte, bool) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	var (
+		items  [][]byte
+		root   = int64(-1)
+		single bool
+	)
+	for {
+		off := d.InputOffset()
+		tok, err := d.Token()
+		if err != nil {
+			return nil, false
+		}
+		el, ok := tok.(xml.StartElement)
+		if !ok {
+			if _, end := tok.(xml.EndElement); end {
+				break
+			}
+			continue
+		}
+		if root == -1 {
+			root = off
+			continue
+		}
+		if el.Name.Local == "ip" {
+			single = true
+		}
+		if err := d.Skip(); err != nil {
+			return nil, false
+		}
+		items = append(items, bytes.TrimSpace(data[off:d.InputOffset()]))
+	}
+	if single {
+		return [][]byte{bytes.TrimSpace(data[root:d.InputOffset()])}, true
+	}
+	return items, true
+}
+
+// 'fanOutXML' is the same as 'fanOut' but for XML response.
+// The restored response has "results" root element and one child element
+// per each passed IP address.
+func (r *RawResponse) fanOutXML(unique int, positions []int) {
+	if apiErr, err := checkXMLError(r.RawData); err != nil || apiErr != nil {
+		return
+	}
+	items, ok := xmlItems(r.RawData)
+	if !ok {
+		return
+	}
+	if len(items) != unique {
+		r.fail(fmt.Errorf("%w (%d, expected %d)", ErrResponseMismatch, len(items), unique))
+		return
+	}
+	if unique > 1 && unique == len(positions) {
+		return // Nothing to restore
+	}
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString("<results>")
+	for _, pos := range positions {
+		buf.Write(items[pos])
+	}
+	buf.WriteString("</results>")
+	r.RawData = buf.Bytes()
+}
@@ -0,0 +1,190 @@
+// Copyright © 2019. All rights reserved.
+// Author: Alice Qio.
+// Contacts: <qioalice@gmail.com>.
+// License: https://opensource.org/licenses/MIT
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom
+// the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NON INFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package ipstack
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// 'usageLimitXML' is the XML body of Web API error with 104 code.
+const usageLimitXML = `<?xml version="1.0" encoding="UTF-8"?>
+<result>
+	<success>false</success>
+	<error>
+		<code>104</code>
+		<type>usage_limit_reached</type>
+		<info>Your monthly API request volume has been reached.</info>
+	</error>
+</result>`
+
+// 'fullResponseXML' is the XML body of successful response
+// with nested objects.
+const fullResponseXML = `<?xml version="1.0" encoding="UTF-8"?>
+<result>
+	<ip>134.201.250.155</ip>
+	<type>ipv4</type>
+	<country_code>US</country_code>
+	<city>Los Angeles</city>
+	<latitude>34.0453</latitude>
+	<longitude>-118.2413</longitude>
+	<location>
+		<geoname_id>5368361</geoname_id>
+		<capital>Washington D.C.</capital>
+		<languages>
+			<code>en</code>
+			<name>English</name>
+			<native>English</native>
+		</languages>
+		<languages>
+			<code>es</code>
+			<name>Spanish</name>
+			<native>Español</native>
+		</languages>
+		<is_eu>false</is_eu>
+	</location>
+	<time_zone>
+		<id>America/Los_Angeles</id>
+		<current_time>2025-03-08 17:30:00</current_time>
+		<gmt_offset>-28800</gmt_offset>
+		<code>PST</code>
+		<is_daylight_saving>false</is_daylight_saving>
+	</time_zone>
+	<security>
+		<is_proxy>true</is_proxy>
+		<proxy_type>vpn</proxy_type>
+		<is_tor>true</is_tor>
+		<threat_level>high</threat_level>
+		<threat_types>tor, attack_source</threat_types>
+	</security>
+</result>`
+
+func TestXMLCheckError(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		err  error
+	}{
+		{"Error", usageLimitXML, ErrUsageLimitReached},
+		{"Success", fullResponseXML, nil},
+		{"SuccessWithError", `<result><success>true</success><error><code>104</code></error></result>`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := &RawResponse{RawData: []byte(tt.body), format: FormatXML}
+			err := rr.CheckError()
+			if tt.err == nil {
+				if err != nil {
+					t.Fatalf("CheckError: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("CheckError: got %v, expected %v", err, tt.err)
+			}
+			var apiErr *APIErr
+			if !errors.As(err, &apiErr) || apiErr.RawCode != 104 || apiErr.RawType != "usage_limit_reached" {
+				t.Fatalf("CheckError: got %#v", apiErr)
+			}
+			// 'DecodeTo' returns the same error
+			if err := rr.DecodeTo(new(Response)); !errors.Is(err, tt.err) {
+				t.Fatalf("DecodeTo: got %v, expected %v", err, tt.err)
+			}
+		})
+	}
+
+	rr := &RawResponse{RawData: []byte(`<result><ip>`), format: FormatXML}
+	var opErr *OpError
+	if err := rr.CheckError(); !errors.As(err, &opErr) {
+		t.Fatalf("CheckError: got %v, expected OpError", err)
+	}
+}
+
+func TestXMLClientError(t *testing.T) {
+	c := newFakeClient(t, replyWith(usageLimitXML), ParamFormat(FormatXML))
+	if _, err := c.IP("1.1.1.1"); !errors.Is(err, ErrUsageLimitReached) {
+		t.Fatalf("IP: got %v, expected ErrUsageLimitReached", err)
+	}
+}
+
+func TestXMLDecodeResponse(t *testing.T) {
+	c := newFakeClient(t, replyWith(fullResponseXML), ParamFormat(FormatXML))
+	res, err := c.IP("134.201.250.155")
+	if err != nil {
+		t.Fatalf("IP: %v", err)
+	}
+	if res.IP != "134.201.250.155" || res.City != "Los Angeles" || res.Latitide != 34.0453 {
+		t.Fatalf("IP: got %+v", res)
+	}
+
+	loc := res.Location
+	if loc == nil || loc.GeonameID != 5368361 || loc.Capital != "Washington D.C." {
+		t.Fatalf("location: got %+v", loc)
+	}
+	if len(loc.Languages) != 2 || loc.Languages[1].Code != "es" || loc.Languages[1].NativeName != "Español" {
+		t.Fatalf("location.languages: got %+v", loc.Languages)
+	}
+
+	tz := res.Timezone
+	if tz == nil || tz.ID != "America/Los_Angeles" || tz.GMTOffset != -28800 || tz.Code != "PST" {
+		t.Fatalf("time_zone: got %+v", tz)
+	}
+	// Time w/o offset is treated in 'GMTOffset' zone
+	if want := time.Date(2025, 3, 9, 1, 30, 0, 0, time.UTC); !tz.CurrentTime.Equal(want) {
+		t.Fatalf("time_zone.current_time: got %v, expected %v", tz.CurrentTime, want)
+	}
+
+	sec := res.Security
+	if sec == nil || !sec.IsProxy || sec.ProxyType != ProxyVPN || !sec.IsTOR || sec.ThreatLevel != ThreatLevelHigh {
+		t.Fatalf("security: got %+v", sec)
+	}
+	if len(sec.ThreatTypes) != 2 || !sec.ThreatTypes.Has(ThreatTOR) || !sec.ThreatTypes.Has(ThreatAttackSource) {
+		t.Fatalf("security.threat_types: got %v", sec.ThreatTypes)
+	}
+}
+
+func TestXMLDecodeTo(t *testing.T) {
+	var dst struct {
+		City     string `xml:"city"`
+		Location struct {
+			Capital string `xml:"capital"`
+		} `xml:"location"`
+		TimeZone *TimeZone   `xml:"time_zone"`
+		Threats  ThreatTypes `xml:"security>threat_types"`
+	}
+	rr := &RawResponse{RawData: []byte(fullResponseXML), format: FormatXML}
+	if err := rr.DecodeTo(&dst); err != nil {
+		t.Fatalf("DecodeTo: %v", err)
+	}
+	if dst.City != "Los Angeles" || dst.Location.Capital != "Washington D.C." {
+		t.Fatalf("DecodeTo: got %+v", dst)
+	}
+	if dst.TimeZone == nil || dst.TimeZone.CurrentTime.IsZero() {
+		t.Fatalf("DecodeTo: got time zone %+v", dst.TimeZone)
+	}
+	if len(dst.Threats) != 2 || dst.Threats[0] != ThreatTOR {
+		t.Fatalf("DecodeTo: got threat types %v", dst.Threats)
+	}
+}